@@ -1,15 +1,22 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"log"
+	"net/http"
 	"time"
 
 	"distributed-sensor-fusion/command"
 )
 
 func main() {
+	worldAddr := flag.String("world", "localhost:50051", "World server address, followed for the world's size and edges")
+	flag.Parse()
+
 	// Cluster readings within 10 units, require 2+ sensors to confirm
 	server := command.NewCommandServer(10.0, 2)
+	go server.FollowWorld(context.Background(), *worldAddr)
 	wsHub := command.NewWebSocketHub()
 
 	// Forward confirmed threats to WebSocket
@@ -21,15 +28,29 @@ func main() {
 		}
 	}()
 
-	// Periodic cleanup of stale threats
+	// Forward sensor up/down events to WebSocket
+	go func() {
+		for evt := range server.Registry().Events() {
+			wsHub.BroadcastSensorEvent(evt)
+		}
+	}()
+
+	// Periodic cleanup of stale threats, silent sensors and batch sessions.
+	// Threats on dead reckoning are moved along and rebroadcast first.
 	go func() {
 		ticker := time.NewTicker(1 * time.Second)
 		for range ticker.C {
+			for _, threat := range server.Fusion().Extrapolate() {
+				wsHub.BroadcastThreat(threat)
+			}
 			server.Fusion().Cleanup()
+			server.Registry().Sweep()
+			server.Dedup().Cleanup()
 		}
 	}()
 
-	// Start WebSocket server
+	// Start WebSocket server, with the sensor registry alongside
+	http.Handle("/sensors", server.Registry())
 	go wsHub.Start(":8080")
 
 	// Start gRPC server (blocks)
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"distributed-sensor-fusion/sensor"
+)
+
+// Exit codes
+const (
+	exitOK    = 0
+	exitError = 1 // Failed while running
+	exitUsage = 2 // Bad flags or config
+)
+
+// config is a single sensor plus where it connects. The config file holds
+// the same fields as a fleet entry alongside the addresses.
+type config struct {
+	WorldAddr   string `json:"world_addr"`
+	CommandAddr string `json:"command_addr"`
+	QueueFile   string `json:"queue_file"`
+	TruthFile   string `json:"truth_file"`
+	sensor.SensorSpec
+}
+
+func main() {
+	os.Exit(run())
+}
+
+func run() int {
+	cfg := config{
+		WorldAddr:   "localhost:50051",
+		CommandAddr: "localhost:50052",
+	}
+
+	fs := flag.NewFlagSet("sensor", flag.ContinueOnError)
+	configPath := fs.String("config", "", "JSON config file; flags given explicitly override it")
+	fs.StringVar(&cfg.ID, "id", cfg.ID, "Sensor ID (required)")
+	fs.StringVar(&cfg.Type, "type", cfg.Type, "Sensor type: radar, eo or acoustic")
+	fs.StringVar(&cfg.WorldAddr, "world", cfg.WorldAddr, "World server address")
+	fs.StringVar(&cfg.CommandAddr, "command", cfg.CommandAddr, "Command server address")
+	fs.Float64Var(&cfg.X, "x", cfg.X, "Sensor X position")
+	fs.Float64Var(&cfg.Y, "y", cfg.Y, "Sensor Y position")
+	fs.Float64Var(&cfg.Range, "range", cfg.Range, "Coverage radius, 0 for the whole world")
+	fs.Float64Var(&cfg.Rate, "rate", cfg.Rate, "Scans per second on the sensor's own schedule, 0 to scan every world state")
+	fs.Float64Var(&cfg.Phase, "phase", cfg.Phase, "Seconds into each scan period at which the sensor scans")
+	fs.BoolVar(&cfg.Interpolate, "interpolate", cfg.Interpolate, "Advance truth positions to the scan time")
+	fs.StringVar(&cfg.QueueFile, "queue-file", cfg.QueueFile, "Persist unsent readings here across restarts")
+	fs.StringVar(&cfg.TruthFile, "truth-file", cfg.TruthFile, "Record the ground truth behind every report here as JSON lines")
+	worldTimeout := fs.Duration("world-timeout", 0, "Exit with an error after the world server is unreachable this long, 0 to retry for ever")
+	noiseJSON := fs.String("noise", "", `Noise overrides as JSON, e.g. {"model":"gauss-markov"}`)
+	noiseModel := fs.String("noise-model", "", "Position error model")
+	stdDev := fs.Float64("std-dev", 0, "Position noise standard deviation")
+	missRate := fs.Float64("miss-rate", 0, "Probability of missing a threat without a detection model")
+	falseAlarms := fs.Float64("false-alarm-rate", 0, "Expected false alarms per scan over the whole world")
+	localTracking := fs.Bool("local-tracking", false, "Report local tracks instead of raw detections")
+	bearingOnly := fs.Bool("bearing-only", false, "Passive sensor: report bearings instead of positions")
+	tdoa := fs.Bool("tdoa", false, "Acoustic array element: report arrival times instead of positions")
+	deadReckoning := fs.Bool("dead-reckoning", false, "Suppress readings the command server can predict")
+	cfg.Adversary.RegisterFlags(fs)
+	cfg.Timing.RegisterFlags(fs)
+
+	args := os.Args[1:]
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if *configPath != "" {
+		data, err := os.ReadFile(*configPath)
+		if err == nil {
+			err = sensor.DecodeStrict(*configPath, data, &cfg)
+		}
+		if err != nil {
+			log.Printf("Failed to load config: %v", err)
+			return exitUsage
+		}
+		// Parse again so explicit flags win over the file
+		if err := fs.Parse(args); err != nil {
+			return exitUsage
+		}
+	}
+
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	if set["noise"] {
+		cfg.Noise = json.RawMessage(*noiseJSON)
+	}
+	if *localTracking && len(cfg.LocalTracking) == 0 {
+		cfg.LocalTracking = json.RawMessage("{}")
+	}
+	if *bearingOnly {
+		cfg.BearingOnly = true
+	}
+	if *tdoa {
+		cfg.TDOA = true
+	}
+	if *deadReckoning && len(cfg.DeadReckoning) == 0 {
+		cfg.DeadReckoning = json.RawMessage("{}")
+	}
+
+	if cfg.ID == "" {
+		log.Printf("A sensor ID is required (-id or \"id\" in the config)")
+		fs.Usage()
+		return exitUsage
+	}
+
+	// Fold the individual noise flags into the spec's overrides
+	nc, err := cfg.NoiseConfig()
+	if err != nil {
+		log.Printf("Invalid sensor config: %v", err)
+		return exitUsage
+	}
+	if set["noise-model"] {
+		nc.Model = sensor.NoiseModel(*noiseModel)
+	}
+	if set["std-dev"] {
+		nc.PositionStdDev = *stdDev
+	}
+	if set["miss-rate"] {
+		nc.MissRate = *missRate
+	}
+	if set["false-alarm-rate"] {
+		nc.FalsePositiveRate = *falseAlarms
+	}
+	if cfg.Noise, err = json.Marshal(nc); err != nil {
+		log.Printf("Invalid sensor config: %v", err)
+		return exitUsage
+	}
+
+	s, err := cfg.NewSensor(cfg.WorldAddr)
+	if err != nil {
+		log.Printf("Invalid sensor config: %v", err)
+		return exitUsage
+	}
+	s.WorldTimeout = *worldTimeout
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	opts := sensor.DefaultClientOptions()
+	opts.QueueFile = cfg.QueueFile
+	client := sensor.NewCommandClientWithOptions(cfg.CommandAddr, opts)
+	if err := client.Connect(ctx); err != nil {
+		log.Printf("Failed to connect to command server: %v", err)
+		return exitError
+	}
+	defer func() {
+		st := client.Status()
+		log.Printf("[%s] Shutting down: acked=%d queued=%d dropped=%d",
+			s.ID, st.Acked, st.QueueDepth, st.Dropped)
+		client.Close()
+	}()
+
+	if cfg.TruthFile != "" {
+		f, err := os.Create(cfg.TruthFile)
+		if err != nil {
+			log.Printf("Failed to open truth file: %v", err)
+			return exitError
+		}
+		truthCtx, stopTruth := context.WithCancel(ctx)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			if err := s.RecordTruth(truthCtx, f); err != nil {
+				log.Printf("[%s] Stopped recording truth: %v", s.ID, err)
+			}
+		}()
+		defer func() {
+			stopTruth()
+			<-done
+			f.Close()
+		}()
+	}
+
+	go client.KeepRegistered(ctx, s.Registration())
+	go forward(ctx, s, client)
+
+	log.Printf("[%s] Starting sensor type=%q at (%.1f, %.1f), world %s, command %s",
+		s.ID, s.Type, s.Coverage.X, s.Coverage.Y, cfg.WorldAddr, cfg.CommandAddr)
+	if err := s.Start(ctx); err != nil && !errors.Is(err, context.Canceled) {
+		log.Printf("[%s] Sensor error: %v", s.ID, err)
+		return exitError
+	}
+	return exitOK
+}
+
+func forward(ctx context.Context, s *sensor.Sensor, client *sensor.CommandClient) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case reading := <-s.Readings():
+			if err := client.Send(reading); err != nil {
+				log.Printf("[%s] Send error: %v", s.ID, err)
+			}
+		case track := <-s.Tracks():
+			if err := client.SendTrack(track); err != nil {
+				log.Printf("[%s] Send error: %v", s.ID, err)
+			}
+		case bearing := <-s.Bearings():
+			if err := client.SendBearing(bearing); err != nil {
+				log.Printf("[%s] Send error: %v", s.ID, err)
+			}
+		case arrival := <-s.Arrivals():
+			if err := client.SendArrival(arrival); err != nil {
+				log.Printf("[%s] Send error: %v", s.ID, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os/signal"
+	"syscall"
+
+	"distributed-sensor-fusion/sensor"
+)
+
+func main() {
+	configPath := flag.String("config", "fleet.json", "Fleet config file")
+	flag.Parse()
+
+	cfg, err := sensor.LoadFleetConfig(*configPath)
+	if err != nil {
+		log.Fatalf("Failed to load fleet config: %v", err)
+	}
+
+	fleet, err := sensor.NewFleet(cfg)
+	if err != nil {
+		log.Fatalf("Failed to create fleet: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	log.Printf("Starting fleet of %d sensors", len(cfg.Sensors))
+	if err := fleet.Run(ctx); err != nil {
+		log.Fatalf("Fleet error: %v", err)
+	}
+	log.Println("Fleet stopped")
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"flag"
 	"log"
 	"os"
 	"os/signal"
@@ -10,14 +11,41 @@ import (
 	"distributed-sensor-fusion/sensor"
 )
 
+// Usage: sensortest [adversary flags] [id] [noise model] [sensor type]
 func main() {
+	var adversary sensor.AdversaryConfig
+	adversary.RegisterFlags(flag.CommandLine)
+	flag.Parse()
+	args := flag.Args()
+
 	sensorID := "sensor-1"
-	if len(os.Args) > 1 {
-		sensorID = os.Args[1]
+	if len(args) > 0 {
+		sensorID = args[0]
 	}
 
 	noise := sensor.DefaultNoiseConfig()
-	s := sensor.NewSensor(sensorID, "localhost:50051", noise)
+	if len(args) > 1 {
+		noise.Model = sensor.NoiseModel(args[1])
+	}
+	sensorType := ""
+	if len(args) > 2 {
+		sensorType = args[2]
+		var err error
+		if noise.Detection, err = sensor.DetectionModelFor(sensorType); err != nil {
+			log.Fatalf("Invalid sensor: %v", err)
+		}
+		if noise.Velocity, err = sensor.VelocityModelFor(sensorType); err != nil {
+			log.Fatalf("Invalid sensor: %v", err)
+		}
+	}
+	s, err := sensor.NewSensor(sensorID, "localhost:50051", noise)
+	if err != nil {
+		log.Fatalf("Invalid sensor: %v", err)
+	}
+	s.Type = sensorType
+	if adversary.Enabled() {
+		s.EnableAdversary(adversary)
+	}
 	client := sensor.NewCommandClient("localhost:50052")
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -37,6 +65,8 @@ func main() {
 	}
 	defer client.Close()
 
+	go client.KeepRegistered(ctx, s.Registration())
+
 	// Forward readings to command server
 	go func() {
 		for reading := range s.Readings() {
@@ -1,18 +1,59 @@
 package main
 
 import (
+	"flag"
 	"log"
 	"time"
 
 	"distributed-sensor-fusion/world"
 )
 
-const tickRate = 500 * time.Millisecond
-
 func main() {
+	tickRate := flag.Duration("tick", 500*time.Millisecond, "Time between ticks; change it at runtime with /speed")
+	obstaclesPath := flag.String("obstacles", "", "JSON file of line-of-sight obstacles")
+	scenarioPath := flag.String("scenario", "", "JSON scenario file; replaces the 3 random threats")
+	populationPath := flag.String("population", "", "JSON file of threat birth and death processes")
+	boundary := flag.String("boundary", "", "World edges: torus, reflect or absorb (default torus, or the scenario's)")
+	flag.Parse()
+
 	server := world.NewWorldServer(3, 100.0, 100.0)
+	if *scenarioPath != "" {
+		scenario, err := world.LoadScenario(*scenarioPath)
+		if err != nil {
+			log.Fatalf("Failed to load scenario: %v", err)
+		}
+		server = world.NewWorldServerFromScenario(scenario)
+		log.Printf("Loaded scenario %s: %d threats in a %gx%g world",
+			*scenarioPath, len(scenario.Threats), scenario.Width, scenario.Height)
+	}
+
+	if *boundary != "" {
+		if err := server.SetBoundary(*boundary); err != nil {
+			log.Fatalf("Invalid -boundary: %v", err)
+		}
+	}
+
+	if *obstaclesPath != "" {
+		obstacles, err := world.LoadObstacles(*obstaclesPath)
+		if err != nil {
+			log.Fatalf("Failed to load obstacles: %v", err)
+		}
+		if err := server.SetObstacles(obstacles); err != nil {
+			log.Fatalf("Failed to load obstacles: %v", err)
+		}
+	}
+
+	if *populationPath != "" {
+		population, err := world.LoadPopulation(*populationPath)
+		if err != nil {
+			log.Fatalf("Failed to load population: %v", err)
+		}
+		if err := server.SetPopulation(population); err != nil {
+			log.Fatalf("Failed to load population: %v", err)
+		}
+	}
 
-	go server.RunSimulation(tickRate)
+	go server.RunSimulation(*tickRate)
 	go server.StartControlServer(":8081")
 
 	if err := server.Start(":50051"); err != nil {
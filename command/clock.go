@@ -0,0 +1,85 @@
+package command
+
+import (
+	"sync"
+	"time"
+)
+
+// clockWindow holds recent arrival-minus-stamp samples for one sensor
+type clockWindow struct {
+	samples []time.Duration
+	next    int
+}
+
+// ClockEstimator estimates each sensor's clock offset from when its
+// reports arrive. Every sample of arrival time minus sensor timestamp is
+// the link latency minus the clock offset; latency is never negative and
+// the fastest reports have close to none, so the minimum over a recent
+// window tracks the offset (and follows drift as old samples fall out).
+type ClockEstimator struct {
+	mu      sync.Mutex
+	sensors map[string]*clockWindow
+	window  int
+}
+
+func NewClockEstimator(window int) *ClockEstimator {
+	return &ClockEstimator{
+		sensors: make(map[string]*clockWindow),
+		window:  window,
+	}
+}
+
+// Correct records a report's arrival and returns its timestamp moved onto
+// the command server's clock
+func (c *ClockEstimator) Correct(sensorID string, stamp int64, arrival time.Time) int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	w, exists := c.sensors[sensorID]
+	if !exists {
+		w = &clockWindow{samples: make([]time.Duration, 0, c.window)}
+		c.sensors[sensorID] = w
+	}
+
+	sample := arrival.Sub(time.Unix(0, stamp))
+	if len(w.samples) < c.window {
+		w.samples = append(w.samples, sample)
+	} else {
+		w.samples[w.next] = sample
+		w.next = (w.next + 1) % c.window
+	}
+
+	return stamp + int64(w.correction())
+}
+
+// Offset returns how far ahead of the command server a sensor's clock is
+// believed to be, and whether there is an estimate yet
+func (c *ClockEstimator) Offset(sensorID string) (time.Duration, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	w, exists := c.sensors[sensorID]
+	if !exists || len(w.samples) == 0 {
+		return 0, false
+	}
+	return -w.correction(), true
+}
+
+// correction is the smallest arrival-minus-stamp seen, i.e. minus the offset
+func (w *clockWindow) correction() time.Duration {
+	lowest := w.samples[0]
+	for _, s := range w.samples[1:] {
+		if s < lowest {
+			lowest = s
+		}
+	}
+	return lowest
+}
+
+// Forget drops a sensor's samples, e.g. when it re-registers after a
+// restart and its clock may have been reset
+func (c *ClockEstimator) Forget(sensorID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.sensors, sensorID)
+}
@@ -0,0 +1,81 @@
+package command
+
+import (
+	"sync"
+	"time"
+)
+
+// dedupWindow is how many sequence numbers behind the newest we remember
+const dedupWindow = 1024
+
+type batchSession struct {
+	sensorID  string
+	sessionID uint64
+}
+
+type seqWindow struct {
+	highest  uint64
+	seen     map[uint64]struct{}
+	lastSeen time.Time
+}
+
+// BatchDeduplicator remembers which reading batches have been processed so
+// retransmissions after a reconnect aren't fused twice
+type BatchDeduplicator struct {
+	mu         sync.Mutex
+	sessions   map[batchSession]*seqWindow
+	sessionTTL time.Duration
+}
+
+func NewBatchDeduplicator(sessionTTL time.Duration) *BatchDeduplicator {
+	return &BatchDeduplicator{
+		sessions:   make(map[batchSession]*seqWindow),
+		sessionTTL: sessionTTL,
+	}
+}
+
+// Check records the batch and reports whether it was already seen.
+// Anything older than the window is assumed seen.
+func (d *BatchDeduplicator) Check(sensorID string, sessionID, seq uint64) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	key := batchSession{sensorID: sensorID, sessionID: sessionID}
+	w, exists := d.sessions[key]
+	if !exists {
+		w = &seqWindow{seen: make(map[uint64]struct{})}
+		d.sessions[key] = w
+	}
+	w.lastSeen = time.Now()
+
+	if w.highest >= dedupWindow && seq <= w.highest-dedupWindow {
+		return true
+	}
+	if _, dup := w.seen[seq]; dup {
+		return true
+	}
+
+	w.seen[seq] = struct{}{}
+	if seq > w.highest {
+		w.highest = seq
+		for s := range w.seen {
+			if w.highest >= dedupWindow && s <= w.highest-dedupWindow {
+				delete(w.seen, s)
+			}
+		}
+	}
+	return false
+}
+
+// Cleanup forgets sessions that have been quiet past the TTL
+func (d *BatchDeduplicator) Cleanup() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	for key, w := range d.sessions {
+		if now.Sub(w.lastSeen) > d.sessionTTL {
+			delete(d.sessions, key)
+		}
+	}
+}
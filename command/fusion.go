@@ -13,11 +13,19 @@ type FusedThreat struct {
 	ID          int
 	X           float64
 	Y           float64
+	VX          float64 // From sensor tracks or velocity measurements, see velocityKnown
+	VY          float64
 	Level       int
 	Confidence  float64
 	SensorCount int
 	LastSeen    time.Time
 	Readings    []*sensorpb.SensorReading
+
+	tracks         []sensorTrack
+	confirmed      bool // Once confirmed, stays so while any sensor still sees it
+	velocityKnown  bool // Readings' velocity measurements determine VX/VY
+	triangulated   bool // Built from bearing-only sensors, see ProcessBearing
+	multilaterated bool // Built from TDOA arrival times, see ProcessArrival
 }
 
 type FusionEngine struct {
@@ -29,6 +37,11 @@ type FusionEngine struct {
 	expirationTime time.Duration
 	worldWidth     float64
 	worldHeight    float64
+	bounded        bool                                  // World has edges rather than wrapping, see SetWorld
+	trackAssoc     map[trackKey]int                      // Local sensor track -> fused threat ID
+	bearings       map[string][]*sensorpb.BearingReading // Latest scan per passive sensor
+	emissions      map[emissionKey]*emission             // Arrivals awaiting multilateration
+	sourceAssoc    map[int32]int                         // Acoustic signature -> fused threat ID
 }
 
 func NewFusionEngine(clusterRadius float64, minSensors int) *FusionEngine {
@@ -40,6 +53,10 @@ func NewFusionEngine(clusterRadius float64, minSensors int) *FusionEngine {
 		expirationTime: 2 * time.Second,
 		worldWidth:     100.0,
 		worldHeight:    100.0,
+		trackAssoc:     make(map[trackKey]int),
+		bearings:       make(map[string][]*sensorpb.BearingReading),
+		emissions:      make(map[emissionKey]*emission),
+		sourceAssoc:    make(map[int32]int),
 	}
 }
 
@@ -47,16 +64,30 @@ func (f *FusionEngine) ProcessReading(reading *sensorpb.SensorReading) *FusedThr
 	f.mu.Lock()
 	defer f.mu.Unlock()
 
-	// Try to match to existing threat
+	// Reports delayed past expiry would only drag threats backwards
+	if f.stale(reading, time.Now()) {
+		return nil
+	}
+
+	// Match to the nearest existing threat whose motion agrees
+	var match *FusedThreat
+	best := f.clusterRadius
 	for _, threat := range f.threats {
+		if len(threat.tracks) > 0 || threat.triangulated || threat.multilaterated {
+			continue
+		}
 		dist := f.wrappedDistance(reading.X, reading.Y, threat.X, threat.Y)
-		if dist <= f.clusterRadius {
-			f.updateThreat(threat, reading)
-			if threat.SensorCount >= f.minSensors {
-				return threat
-			}
-			return nil
+		if dist <= best && f.velocityConsistent(threat, reading) {
+			best = dist
+			match = threat
+		}
+	}
+	if match != nil {
+		f.updateThreat(match, reading)
+		if f.isConfirmed(match) {
+			return match
 		}
+		return nil
 	}
 
 	// No match - create pending threat
@@ -80,12 +111,19 @@ func (f *FusionEngine) updateThreat(threat *FusedThreat, reading *sensorpb.Senso
 	// Check if this sensor already contributed
 	for _, r := range threat.Readings {
 		if r.SensorId == reading.SensorId {
+			// A delayed report overtaken by a newer one changes nothing
+			if reading.Timestamp < r.Timestamp {
+				return
+			}
 			// Update existing reading from this sensor
 			r.X = reading.X
 			r.Y = reading.Y
 			r.Level = reading.Level
 			r.Confidence = reading.Confidence
 			r.Timestamp = reading.Timestamp
+			r.DeadReckoning = reading.DeadReckoning
+			r.Velocity = reading.Velocity
+			f.dropStaleReadings(threat)
 			f.recalculate(threat)
 			return
 		}
@@ -93,20 +131,64 @@ func (f *FusionEngine) updateThreat(threat *FusedThreat, reading *sensorpb.Senso
 
 	// New sensor contributing
 	threat.Readings = append(threat.Readings, reading)
-	threat.SensorCount = len(threat.Readings)
+	f.dropStaleReadings(threat)
 	f.recalculate(threat)
 }
 
+// dropStaleReadings forgets sensors that have stopped seeing the threat,
+// e.g. because it went behind an obstacle from where they stand, so their
+// last position doesn't hold the fused estimate back
+func (f *FusionEngine) dropStaleReadings(threat *FusedThreat) {
+	now := time.Now()
+	live := threat.Readings[:0]
+	for _, r := range threat.Readings {
+		if !f.stale(r, now) {
+			live = append(live, r)
+		}
+	}
+	threat.Readings = live
+	threat.SensorCount = len(live)
+}
+
+// stale reports whether a reading is too old to describe the threat now.
+// Dead-reckoned readings stay current through their silence interval.
+func (f *FusionEngine) stale(r *sensorpb.SensorReading, now time.Time) bool {
+	age := now.Sub(time.Unix(0, r.Timestamp))
+	if r.DeadReckoning != nil {
+		age -= time.Duration(r.DeadReckoning.MaxSilenceMs) * time.Millisecond
+	}
+	return age > f.expirationTime
+}
+
+// isConfirmed applies the sensor threshold with hysteresis: a threat that
+// reached minSensors stays confirmed while fewer sensors can see it
+func (f *FusionEngine) isConfirmed(threat *FusedThreat) bool {
+	if threat.SensorCount >= f.minSensors {
+		threat.confirmed = true
+	}
+	return threat.confirmed
+}
+
 func (f *FusionEngine) recalculate(threat *FusedThreat) {
+	if len(threat.Readings) == 0 {
+		return
+	}
+
 	var sumLevel int
 	var sumConf float64
 
 	// Use circular mean for positions to handle wrap-around
 	var sinX, cosX, sinY, cosY float64
+	var sumX, sumY float64
+	now := time.Now()
 	for _, r := range threat.Readings {
+		x, y := f.predictedPosition(r, now)
+		sumX += x * r.Confidence
+		sumY += y * r.Confidence
+
 		// Convert to angles (0-100 maps to 0-2π)
-		angleX := (r.X / f.worldWidth) * 2 * math.Pi
-		angleY := (r.Y / f.worldHeight) * 2 * math.Pi
+		angleX := (x / f.worldWidth) * 2 * math.Pi
+		angleY := (y / f.worldHeight) * 2 * math.Pi
 
 		// Weight by confidence
 		sinX += math.Sin(angleX) * r.Confidence
@@ -118,39 +200,112 @@ func (f *FusionEngine) recalculate(threat *FusedThreat) {
 		sumLevel += int(r.Level)
 	}
 
-	// Convert back from angles to positions
-	avgAngleX := math.Atan2(sinX, cosX)
-	avgAngleY := math.Atan2(sinY, cosY)
+	if f.bounded && sumConf > 0 {
+		// No seam to average across, so a plain weighted mean
+		threat.X = sumX / sumConf
+		threat.Y = sumY / sumConf
+	} else {
+		// Convert back from angles to positions
+		avgAngleX := math.Atan2(sinX, cosX)
+		avgAngleY := math.Atan2(sinY, cosY)
 
-	// Normalize to 0-100 range
-	threat.X = (avgAngleX / (2 * math.Pi)) * f.worldWidth
-	if threat.X < 0 {
-		threat.X += f.worldWidth
-	}
-	threat.Y = (avgAngleY / (2 * math.Pi)) * f.worldHeight
-	if threat.Y < 0 {
-		threat.Y += f.worldHeight
+		// Normalize to 0-100 range
+		threat.X = (avgAngleX / (2 * math.Pi)) * f.worldWidth
+		if threat.X < 0 {
+			threat.X += f.worldWidth
+		}
+		threat.Y = (avgAngleY / (2 * math.Pi)) * f.worldHeight
+		if threat.Y < 0 {
+			threat.Y += f.worldHeight
+		}
 	}
 
 	threat.Confidence = sumConf / float64(len(threat.Readings))
 	threat.Level = sumLevel / len(threat.Readings)
-	threat.LastSeen = time.Now()
+	threat.LastSeen = now
+
+	f.estimateVelocity(threat)
 }
 
-// wrappedDistance calculates distance accounting for toroidal wrap-around
-func (f *FusionEngine) wrappedDistance(x1, y1, x2, y2 float64) float64 {
-	dx := math.Abs(x2 - x1)
-	dy := math.Abs(y2 - y1)
+// predictedPosition extrapolates a dead-reckoned reading to now. The
+// sensor promised to report again before its max silence runs out, so
+// beyond that the reading is left where the prediction stopped.
+func (f *FusionEngine) predictedPosition(r *sensorpb.SensorReading, now time.Time) (float64, float64) {
+	dr := r.DeadReckoning
+	if dr == nil {
+		return r.X, r.Y
+	}
+	elapsed := now.Sub(time.Unix(0, r.Timestamp))
+	if limit := time.Duration(dr.MaxSilenceMs) * time.Millisecond; elapsed > limit {
+		elapsed = limit
+	}
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	return f.wrap(r.X+dr.Vx*elapsed.Seconds(), f.worldWidth),
+		f.wrap(r.Y+dr.Vy*elapsed.Seconds(), f.worldHeight)
+}
 
-	// Check if wrapping around is shorter
-	if dx > f.worldWidth/2 {
-		dx = f.worldWidth - dx
+// predicting reports whether any of the threat's readings come from a
+// sensor that is deliberately silent because the target is on prediction
+func (f *FusionEngine) predicting(threat *FusedThreat, now time.Time) bool {
+	for _, r := range threat.Readings {
+		if r.DeadReckoning == nil {
+			continue
+		}
+		limit := time.Duration(r.DeadReckoning.MaxSilenceMs) * time.Millisecond
+		if now.Sub(time.Unix(0, r.Timestamp)) <= limit {
+			return true
+		}
 	}
-	if dy > f.worldHeight/2 {
-		dy = f.worldHeight - dy
+	return false
+}
+
+// Extrapolate moves threats held by dead-reckoning sensors along their
+// reported velocity, treating silence as "as predicted" so they don't
+// expire. It returns copies of the confirmed threats it moved, for
+// rebroadcast.
+func (f *FusionEngine) Extrapolate() []*FusedThreat {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := time.Now()
+	moved := make([]*FusedThreat, 0)
+	for _, threat := range f.threats {
+		if len(threat.tracks) > 0 || !f.predicting(threat, now) {
+			continue
+		}
+		f.recalculate(threat)
+		if f.isConfirmed(threat) {
+			moved = append(moved, threat.copyLocked())
+		}
 	}
+	return moved
+}
+
+// copyLocked returns a copy of a threat that is safe to use outside the
+// engine. Call with the engine's mu held.
+func (t *FusedThreat) copyLocked() *FusedThreat {
+	c := *t
+	c.Readings = append([]*sensorpb.SensorReading(nil), t.Readings...)
+	c.tracks = append([]sensorTrack(nil), t.tracks...)
+	return &c
+}
+
+// SetWorld describes the world the sensors observe. A bounded world has
+// edges instead of wrapping round, so distances and averages are plain
+// Euclidean ones.
+func (f *FusionEngine) SetWorld(width, height float64, bounded bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.worldWidth = width
+	f.worldHeight = height
+	f.bounded = bounded
+}
 
-	return math.Sqrt(dx*dx + dy*dy)
+// wrappedDistance calculates distance accounting for toroidal wrap-around
+func (f *FusionEngine) wrappedDistance(x1, y1, x2, y2 float64) float64 {
+	return math.Hypot(f.shortestDelta(x1, x2, f.worldWidth), f.shortestDelta(y1, y2, f.worldHeight))
 }
 
 func (f *FusionEngine) GetConfirmedThreats() []*FusedThreat {
@@ -159,7 +314,7 @@ func (f *FusionEngine) GetConfirmedThreats() []*FusedThreat {
 
 	confirmed := make([]*FusedThreat, 0)
 	for _, threat := range f.threats {
-		if threat.SensorCount >= f.minSensors {
+		if threat.confirmed || threat.SensorCount >= f.minSensors {
 			confirmed = append(confirmed, threat)
 		}
 	}
@@ -176,4 +331,14 @@ func (f *FusionEngine) Cleanup() {
 			delete(f.threats, id)
 		}
 	}
+	for key, id := range f.trackAssoc {
+		if _, ok := f.threats[id]; !ok {
+			delete(f.trackAssoc, key)
+		}
+	}
+	for sig, id := range f.sourceAssoc {
+		if _, ok := f.threats[id]; !ok {
+			delete(f.sourceAssoc, sig)
+		}
+	}
 }
\ No newline at end of file
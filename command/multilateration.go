@@ -0,0 +1,268 @@
+package command
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	sensorpb "distributed-sensor-fusion/shared/generated/sensorpb"
+)
+
+const (
+	arrivalWindow  = time.Second // How long an emission's arrivals wait for more sensors
+	minArrivals    = 3           // Sensors needed to fix a source from time differences
+	arrivalGate    = 4.0         // Standard deviations of RMS residual a fix may have
+	searchStep     = 5.0         // Grid spacing of the initial search, world units
+	searchSeeds    = 8           // Most grid minima refined, best first
+	refineMaxSteps = 10
+)
+
+// emissionKey identifies one emission as heard across the array
+type emissionKey struct {
+	pulse     int32
+	signature int32
+}
+
+// emission collects the arrivals of one emission, one per sensor
+type emission struct {
+	arrivals []*sensorpb.ArrivalReading
+	first    time.Time // When the first arrival was received
+}
+
+// ProcessArrival adds an acoustic sensor's arrival time and, once enough
+// sensors have heard the emission, localises its source from the time
+// differences. It returns the updated threat if it is confirmed.
+func (f *FusionEngine) ProcessArrival(a *sensorpb.ArrivalReading) *FusedThreat {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := time.Now()
+	for key, e := range f.emissions {
+		if now.Sub(e.first) > arrivalWindow {
+			delete(f.emissions, key)
+		}
+	}
+
+	key := emissionKey{pulse: a.Pulse, signature: a.Signature}
+	e := f.emissions[key]
+	if e == nil {
+		e = &emission{first: now}
+		f.emissions[key] = e
+	}
+	for i, old := range e.arrivals {
+		if old.SensorId == a.SensorId {
+			e.arrivals = append(e.arrivals[:i], e.arrivals[i+1:]...)
+			break
+		}
+	}
+	e.arrivals = append(e.arrivals, a)
+	if len(e.arrivals) < minArrivals {
+		return nil
+	}
+
+	p, ok := f.resolveFix(f.multilaterate(e.arrivals), a.Signature, len(e.arrivals))
+	if !ok {
+		return nil
+	}
+	threat := f.multilateratedThreat(p[0], p[1], a.Signature, e.arrivals)
+	if f.isConfirmed(threat) {
+		return threat
+	}
+	return nil
+}
+
+// multilaterate finds the source positions that explain the arrival time
+// differences. The unknown emission time is eliminated by centring the
+// ranges, leaving a 2D least squares problem in the position. Range
+// differences are hyperbolae that can cross more than once (three sensors
+// often give two exact solutions), so Gauss-Newton is started from each
+// local minimum of a coarse grid and every distinct fit within the gate is
+// returned.
+func (f *FusionEngine) multilaterate(arrivals []*sensorpb.ArrivalReading) [][2]float64 {
+	n := float64(len(arrivals))
+	t0 := arrivals[0].ArrivalTime
+
+	// Each arrival as a range offset from the first, in world units
+	ranges := make([]float64, len(arrivals))
+	var sigma float64
+	for i, a := range arrivals {
+		ranges[i] = float64(a.ArrivalTime-t0) / float64(time.Second) * a.Speed
+		sigma += a.StdDev * a.Speed / n
+	}
+	var meanRange float64
+	for _, r := range ranges {
+		meanRange += r / n
+	}
+
+	residuals := func(x, y float64) ([]float64, [][2]float64) {
+		dists := make([]float64, len(arrivals))
+		grads := make([][2]float64, len(arrivals))
+		var meanDist float64
+		var meanGrad [2]float64
+		for i, a := range arrivals {
+			dx := f.shortestDelta(a.SensorX, x, f.worldWidth)
+			dy := f.shortestDelta(a.SensorY, y, f.worldHeight)
+			d := math.Max(math.Sqrt(dx*dx+dy*dy), 1e-6)
+			dists[i] = d
+			grads[i] = [2]float64{dx / d, dy / d}
+			meanDist += d / n
+			meanGrad[0] += dx / d / n
+			meanGrad[1] += dy / d / n
+		}
+		res := make([]float64, len(arrivals))
+		for i := range arrivals {
+			res[i] = (ranges[i] - meanRange) - (dists[i] - meanDist)
+			grads[i][0] -= meanGrad[0]
+			grads[i][1] -= meanGrad[1]
+		}
+		return res, grads
+	}
+	cost := func(res []float64) float64 {
+		var c float64
+		for _, r := range res {
+			c += r * r
+		}
+		return c
+	}
+
+	// Local minima of the cost over the grid, wrapped on a torus, seed the
+	// refinement
+	cols, rows := int(f.worldWidth/searchStep), int(f.worldHeight/searchStep)
+	grid := make([][]float64, cols)
+	for i := range grid {
+		grid[i] = make([]float64, rows)
+		for j := range grid[i] {
+			res, _ := residuals((float64(i)+0.5)*searchStep, (float64(j)+0.5)*searchStep)
+			grid[i][j] = cost(res)
+		}
+	}
+	type seed struct{ x, y, cost float64 }
+	seeds := make([]seed, 0)
+	for i := range grid {
+		for j := range grid[i] {
+			local := true
+			for di := -1; di <= 1 && local; di++ {
+				for dj := -1; dj <= 1; dj++ {
+					ni, nj := i+di, j+dj
+					if f.bounded && (ni < 0 || ni >= cols || nj < 0 || nj >= rows) {
+						continue
+					}
+					if (di != 0 || dj != 0) && grid[(ni+cols)%cols][(nj+rows)%rows] < grid[i][j] {
+						local = false
+						break
+					}
+				}
+			}
+			if local {
+				seeds = append(seeds, seed{(float64(i) + 0.5) * searchStep, (float64(j) + 0.5) * searchStep, grid[i][j]})
+			}
+		}
+	}
+	sort.Slice(seeds, func(i, j int) bool { return seeds[i].cost < seeds[j].cost })
+
+	limit := arrivalGate * math.Max(sigma, 0.01)
+	fixes := make([][2]float64, 0, 2)
+	for _, sd := range seeds[:min(searchSeeds, len(seeds))] {
+		// Gauss-Newton: residual r = measured - predicted, so the step
+		// solves (JᵀJ)δ = Jᵀr with J the gradient of the predicted range
+		x, y := sd.x, sd.y
+		for step := 0; step < refineMaxSteps; step++ {
+			res, grads := residuals(x, y)
+			var a [2][2]float64
+			var b [2]float64
+			for i, g := range grads {
+				a[0][0] += g[0] * g[0]
+				a[0][1] += g[0] * g[1]
+				a[1][0] += g[0] * g[1]
+				a[1][1] += g[1] * g[1]
+				b[0] += g[0] * res[i]
+				b[1] += g[1] * res[i]
+			}
+			if a[0][0]*a[1][1]-a[0][1]*a[1][0] < 1e-9 {
+				break // Degenerate geometry, e.g. collinear sensors
+			}
+			inv := invert2(a)
+			dx := inv[0][0]*b[0] + inv[0][1]*b[1]
+			dy := inv[1][0]*b[0] + inv[1][1]*b[1]
+			x = f.wrap(x+dx, f.worldWidth)
+			y = f.wrap(y+dy, f.worldHeight)
+			if dx*dx+dy*dy < 1e-6 {
+				break
+			}
+		}
+
+		res, _ := residuals(x, y)
+		if math.Sqrt(cost(res)/n) > limit {
+			continue
+		}
+		distinct := true
+		for _, p := range fixes {
+			if f.wrappedDistance(x, y, p[0], p[1]) <= f.clusterRadius {
+				distinct = false
+				break
+			}
+		}
+		if distinct {
+			fixes = append(fixes, [2]float64{x, y})
+		}
+	}
+	return fixes
+}
+
+// resolveFix picks the true source among the multilateration fixes. A
+// lone fix from more sensors than the minimum is overdetermined and taken
+// as is. With just enough sensors a wrong crossing can fit as well as the
+// right one even when only one was found, so then the fix nearest where
+// the source was last placed wins; the signature, which is ground truth,
+// says which threat that is. A new source with a lone fix is taken
+// on trust; with several, the emission is left unlocated until more
+// sensors hear it.
+func (f *FusionEngine) resolveFix(fixes [][2]float64, signature int32, sensors int) ([2]float64, bool) {
+	var pick [2]float64
+	if len(fixes) == 0 {
+		return pick, false
+	}
+	threat := f.threats[f.sourceAssoc[signature]]
+	if len(fixes) == 1 && (sensors > minArrivals || threat == nil) {
+		return fixes[0], true
+	}
+	if threat == nil {
+		return pick, false
+	}
+	found := false
+	best := f.clusterRadius
+	for _, p := range fixes {
+		if dist := f.wrappedDistance(p[0], p[1], threat.X, threat.Y); dist <= best {
+			best, pick, found = dist, p, true
+		}
+	}
+	return pick, found
+}
+
+// multilateratedThreat moves the source's threat onto a fix, or starts a
+// new one. The signature ties emissions to a threat the way local track
+// IDs do, and like triangulated threats these are their own pool. It is
+// the source's true ID (see ArrivalReading), so this association can't go
+// wrong the way a geometric one could.
+func (f *FusionEngine) multilateratedThreat(x, y float64, signature int32, arrivals []*sensorpb.ArrivalReading) *FusedThreat {
+	threat := f.threats[f.sourceAssoc[signature]]
+	if threat == nil {
+		threat = &FusedThreat{ID: f.nextID, multilaterated: true}
+		f.threats[f.nextID] = threat
+		f.sourceAssoc[signature] = f.nextID
+		f.nextID++
+	}
+
+	var conf float64
+	var level int
+	for _, a := range arrivals {
+		conf += a.Confidence
+		level += int(a.Level)
+	}
+	threat.X, threat.Y = x, y
+	threat.Confidence = conf / float64(len(arrivals))
+	threat.Level = level / len(arrivals)
+	threat.SensorCount = len(arrivals)
+	threat.LastSeen = time.Now()
+	return threat
+}
@@ -0,0 +1,171 @@
+package command
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	sensorpb "distributed-sensor-fusion/shared/generated/sensorpb"
+)
+
+// SensorInfo is what the command server knows about one sensor
+type SensorInfo struct {
+	ID             string    `json:"id"`
+	Type           string    `json:"type"`
+	X              float64   `json:"x"`
+	Y              float64   `json:"y"`
+	CoverageRadius float64   `json:"coverage_radius"`
+	NoiseModel     string    `json:"noise_model"`
+	PositionStdDev float64   `json:"position_std_dev"`
+	MissRate       float64   `json:"miss_rate"`
+	FalseAlarmRate float64   `json:"false_alarm_rate"`
+	ClockOffsetMs  float64   `json:"clock_offset_ms"` // Estimated from report arrival times
+	Registered     bool      `json:"registered"`      // False if only known from its data
+	Alive          bool      `json:"alive"`
+	LastSeen       time.Time `json:"last_seen"`
+}
+
+// SensorEvent reports a sensor coming up or going silent
+type SensorEvent struct {
+	Type   string     `json:"type"` // "sensor_up" or "sensor_down"
+	Sensor SensorInfo `json:"sensor"`
+}
+
+// SensorRegistry tracks which sensors exist and whether they are alive
+type SensorRegistry struct {
+	mu                sync.RWMutex
+	sensors           map[string]*SensorInfo
+	heartbeatInterval time.Duration
+	timeout           time.Duration
+	events            chan SensorEvent
+}
+
+func NewSensorRegistry(heartbeatInterval, timeout time.Duration) *SensorRegistry {
+	return &SensorRegistry{
+		sensors:           make(map[string]*SensorInfo),
+		heartbeatInterval: heartbeatInterval,
+		timeout:           timeout,
+		events:            make(chan SensorEvent, 100),
+	}
+}
+
+// Register records or refreshes a sensor's declaration
+func (r *SensorRegistry) Register(reg *sensorpb.SensorRegistration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	info, exists := r.sensors[reg.SensorId]
+	if !exists {
+		info = &SensorInfo{ID: reg.SensorId}
+		r.sensors[reg.SensorId] = info
+	}
+	info.Type = reg.SensorType
+	info.X = reg.X
+	info.Y = reg.Y
+	info.CoverageRadius = reg.CoverageRadius
+	info.NoiseModel = reg.NoiseModel
+	info.PositionStdDev = reg.PositionStdDev
+	info.MissRate = reg.MissRate
+	info.FalseAlarmRate = reg.FalseAlarmRate
+	info.Registered = true
+
+	log.Printf("Sensor %s registered: type=%s pos=(%.1f, %.1f) coverage=%.1f",
+		info.ID, info.Type, info.X, info.Y, info.CoverageRadius)
+	r.markAliveLocked(info)
+}
+
+// Heartbeat refreshes a sensor's liveness. It returns false for sensors
+// that never registered, telling them to register again.
+func (r *SensorRegistry) Heartbeat(sensorID string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	info, exists := r.sensors[sensorID]
+	if !exists || !info.Registered {
+		return false
+	}
+	r.markAliveLocked(info)
+	return true
+}
+
+// Touch counts data from a sensor as proof of life, adding unregistered
+// senders so older clients still show up
+func (r *SensorRegistry) Touch(sensorID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	info, exists := r.sensors[sensorID]
+	if !exists {
+		info = &SensorInfo{ID: sensorID}
+		r.sensors[sensorID] = info
+	}
+	r.markAliveLocked(info)
+}
+
+// SetClockOffset records the estimated offset of a sensor's clock
+func (r *SensorRegistry) SetClockOffset(sensorID string, offset time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if info, exists := r.sensors[sensorID]; exists {
+		info.ClockOffsetMs = float64(offset) / float64(time.Millisecond)
+	}
+}
+
+func (r *SensorRegistry) markAliveLocked(info *SensorInfo) {
+	info.LastSeen = time.Now()
+	if !info.Alive {
+		info.Alive = true
+		r.emit(SensorEvent{Type: "sensor_up", Sensor: *info})
+	}
+}
+
+// Sweep marks sensors down once they have been silent past the timeout
+func (r *SensorRegistry) Sweep() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for _, info := range r.sensors {
+		if info.Alive && now.Sub(info.LastSeen) > r.timeout {
+			info.Alive = false
+			log.Printf("Sensor %s went silent", info.ID)
+			r.emit(SensorEvent{Type: "sensor_down", Sensor: *info})
+		}
+	}
+}
+
+func (r *SensorRegistry) emit(evt SensorEvent) {
+	select {
+	case r.events <- evt:
+	default:
+	}
+}
+
+// Events delivers sensor up/down transitions
+func (r *SensorRegistry) Events() <-chan SensorEvent {
+	return r.events
+}
+
+// Sensors returns a snapshot of all known sensors sorted by ID
+func (r *SensorRegistry) Sensors() []SensorInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	list := make([]SensorInfo, 0, len(r.sensors))
+	for _, info := range r.sensors {
+		list = append(list, *info)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
+
+// ServeHTTP lists the registry as JSON
+func (r *SensorRegistry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(r.Sensors())
+}
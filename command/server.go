@@ -1,10 +1,13 @@
 package command
 
 import (
+	"context"
 	"io"
 	"log"
 	"net"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	sensorpb "distributed-sensor-fusion/shared/generated/sensorpb"
 
@@ -17,14 +20,23 @@ type CommandServer struct {
 	trackers   map[int]*KalmanTracker
 	trackersMu sync.RWMutex
 	broadcast  chan *FusedThreat
+	registry   *SensorRegistry
+	dedup      *BatchDeduplicator
+	clocks     *ClockEstimator
+	tickPeriod atomic.Int64 // World tick length in nanoseconds, see FollowWorld
 }
 
 func NewCommandServer(clusterRadius float64, minSensors int) *CommandServer {
-	return &CommandServer{
+	s := &CommandServer{
 		fusion:    NewFusionEngine(clusterRadius, minSensors),
 		trackers:  make(map[int]*KalmanTracker),
 		broadcast: make(chan *FusedThreat, 100),
+		registry:  NewSensorRegistry(1*time.Second, 3*time.Second),
+		dedup:     NewBatchDeduplicator(time.Minute),
+		clocks:    NewClockEstimator(200),
 	}
+	s.tickPeriod.Store(int64(500 * time.Millisecond)) // The world server's default
+	return s
 }
 
 func (s *CommandServer) StreamReadings(stream sensorpb.SensorService_StreamReadingsServer) error {
@@ -37,38 +49,211 @@ func (s *CommandServer) StreamReadings(stream sensorpb.SensorService_StreamReadi
 			return err
 		}
 
-		log.Printf("Received from %s: threat=%d pos=(%.1f, %.1f)",
-			reading.SensorId, reading.ThreatId, reading.X, reading.Y)
+		s.ingestReading(reading)
+	}
+}
 
-		// Process through fusion engine
-		if confirmed := s.fusion.ProcessReading(reading); confirmed != nil {
-			// Apply Kalman filter
-			s.applyTracking(confirmed)
+// StreamReadingBatches acks every batch by sequence number once its
+// readings have been fused. Retransmitted batches are acked again but
+// not reprocessed.
+func (s *CommandServer) StreamReadingBatches(stream sensorpb.SensorService_StreamReadingBatchesServer) error {
+	for {
+		batch, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
 
-			// Broadcast to WebSocket clients
-			select {
-			case s.broadcast <- confirmed:
-			default:
+		duplicate := s.dedup.Check(batch.SensorId, batch.SessionId, batch.Sequence)
+		if !duplicate {
+			for _, reading := range batch.Readings {
+				if reading.SensorId == "" {
+					reading.SensorId = batch.SensorId
+				}
+				s.ingestReading(reading)
 			}
+		} else {
+			log.Printf("Duplicate batch %d from %s ignored", batch.Sequence, batch.SensorId)
+		}
+
+		if err := stream.Send(&sensorpb.BatchAck{
+			SensorId:  batch.SensorId,
+			SessionId: batch.SessionId,
+			Sequence:  batch.Sequence,
+			Duplicate: duplicate,
+		}); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *CommandServer) ingestReading(reading *sensorpb.SensorReading) {
+	log.Printf("Received from %s: threat=%d pos=(%.1f, %.1f)",
+		reading.SensorId, reading.ThreatId, reading.X, reading.Y)
+	s.registry.Touch(reading.SensorId)
+
+	// Move the timestamp onto our clock before anything compares it
+	reading.Timestamp = s.clocks.Correct(reading.SensorId, reading.Timestamp, time.Now())
+	if offset, ok := s.clocks.Offset(reading.SensorId); ok {
+		s.registry.SetClockOffset(reading.SensorId, offset)
+	}
+
+	// Process through fusion engine
+	if confirmed := s.fusion.ProcessReading(reading); confirmed != nil {
+		// Apply Kalman filter
+		s.applyTracking(confirmed)
+
+		// Broadcast to WebSocket clients
+		s.publish(confirmed)
+	}
+}
+
+// StreamTracks ingests local tracks from sensors running onboard tracking.
+// They are already filtered, so confirmed threats skip the Kalman smoother.
+func (s *CommandServer) StreamTracks(stream sensorpb.SensorService_StreamTracksServer) error {
+	for {
+		track, err := stream.Recv()
+		if err == io.EOF {
+			return stream.SendAndClose(&sensorpb.Ack{Received: true})
+		}
+		if err != nil {
+			return err
+		}
+
+		log.Printf("Track from %s: local=%d pos=(%.1f, %.1f) updates=%d",
+			track.SensorId, track.LocalTrackId, track.X, track.Y, track.UpdateCount)
+		s.registry.Touch(track.SensorId)
+		track.Timestamp = s.clocks.Correct(track.SensorId, track.Timestamp, time.Now())
+
+		if confirmed := s.fusion.ProcessTrack(track); confirmed != nil {
+			s.publish(confirmed)
+		}
+	}
+}
+
+// StreamBearings ingests bearings from passive sensors. Triangulated threats
+// are smoothed and broadcast just like clustered ones.
+func (s *CommandServer) StreamBearings(stream sensorpb.SensorService_StreamBearingsServer) error {
+	for {
+		bearing, err := stream.Recv()
+		if err == io.EOF {
+			return stream.SendAndClose(&sensorpb.Ack{Received: true})
+		}
+		if err != nil {
+			return err
+		}
+
+		log.Printf("Bearing from %s: %.3f rad from (%.1f, %.1f)",
+			bearing.SensorId, bearing.Bearing, bearing.SensorX, bearing.SensorY)
+		s.registry.Touch(bearing.SensorId)
+		bearing.Timestamp = s.clocks.Correct(bearing.SensorId, bearing.Timestamp, time.Now())
+
+		for _, confirmed := range s.fusion.ProcessBearing(bearing) {
+			s.applyTracking(confirmed)
+			s.publish(confirmed)
+		}
+	}
+}
+
+// StreamArrivals ingests arrival times from acoustic array sensors. Both
+// the report and arrival timestamps move onto our clock, since the time
+// differences between sensors are all multilateration has to go on.
+func (s *CommandServer) StreamArrivals(stream sensorpb.SensorService_StreamArrivalsServer) error {
+	for {
+		arrival, err := stream.Recv()
+		if err == io.EOF {
+			return stream.SendAndClose(&sensorpb.Ack{Received: true})
+		}
+		if err != nil {
+			return err
+		}
+
+		log.Printf("Arrival from %s: pulse=%d signature=%d",
+			arrival.SensorId, arrival.Pulse, arrival.Signature)
+		s.registry.Touch(arrival.SensorId)
+		corrected := s.clocks.Correct(arrival.SensorId, arrival.Timestamp, time.Now())
+		arrival.ArrivalTime += corrected - arrival.Timestamp
+		arrival.Timestamp = corrected
+
+		if confirmed := s.fusion.ProcessArrival(arrival); confirmed != nil {
+			s.applyTracking(confirmed)
+			s.publish(confirmed)
 		}
 	}
 }
 
+func (s *CommandServer) RegisterSensor(ctx context.Context, reg *sensorpb.SensorRegistration) (*sensorpb.RegisterResponse, error) {
+	if reg.SensorId == "" {
+		return &sensorpb.RegisterResponse{Accepted: false}, nil
+	}
+	s.registry.Register(reg)
+	s.clocks.Forget(reg.SensorId)
+	return &sensorpb.RegisterResponse{
+		Accepted:            true,
+		HeartbeatIntervalMs: s.registry.heartbeatInterval.Milliseconds(),
+	}, nil
+}
+
+func (s *CommandServer) Heartbeat(ctx context.Context, req *sensorpb.HeartbeatRequest) (*sensorpb.HeartbeatResponse, error) {
+	return &sensorpb.HeartbeatResponse{Known: s.registry.Heartbeat(req.SensorId)}, nil
+}
+
+// applyTracking smooths a confirmed threat's position with its Kalman
+// tracker. The threat belongs to the fusion engine, so it is copied out and
+// written back under the engine's lock.
 func (s *CommandServer) applyTracking(threat *FusedThreat) {
-	s.trackersMu.Lock()
-	defer s.trackersMu.Unlock()
+	s.fusion.mu.RLock()
+	id, x, y, seen := threat.ID, threat.X, threat.Y, threat.LastSeen
+	vx, vy, velocityKnown := threat.VX, threat.VY, threat.velocityKnown
+	s.fusion.mu.RUnlock()
 
-	tracker, exists := s.trackers[threat.ID]
+	s.trackersMu.Lock()
+	tracker, exists := s.trackers[id]
 	if !exists {
 		tracker = NewKalmanTracker(0.1, 1.0)
-		s.trackers[threat.ID] = tracker
+		s.trackers[id] = tracker
 	}
 
-	tracker.Predict(0.1) // Assume 100ms between updates
-	tracker.Update(threat.X, threat.Y)
+	// Velocities are in world units per tick, so predict over the ticks
+	// that have passed since the last update
+	if elapsed := seen.Sub(tracker.last); !tracker.last.IsZero() && elapsed > 0 {
+		tracker.Predict(float64(elapsed) / float64(s.TickPeriod()))
+	}
+	if seen.After(tracker.last) {
+		tracker.last = seen
+	}
+	tracker.Update(x, y)
+	if velocityKnown {
+		tracker.UpdateVelocity(vx, vy, 0.05)
+	}
+	x, y, _, _ = tracker.GetState()
+	s.trackersMu.Unlock()
 
 	// Update threat with smoothed position
-	threat.X, threat.Y, _, _ = tracker.GetState()
+	s.fusion.mu.Lock()
+	threat.X, threat.Y = x, y
+	s.fusion.mu.Unlock()
+}
+
+// publish broadcasts a copy of a confirmed threat, since the fusion engine
+// keeps updating the original
+func (s *CommandServer) publish(threat *FusedThreat) {
+	s.fusion.mu.RLock()
+	threat = threat.copyLocked()
+	s.fusion.mu.RUnlock()
+
+	select {
+	case s.broadcast <- threat:
+	default:
+	}
+}
+
+// TickPeriod is the world's tick length as last measured by FollowWorld,
+// or the world server's default before then
+func (s *CommandServer) TickPeriod() time.Duration {
+	return time.Duration(s.tickPeriod.Load())
 }
 
 func (s *CommandServer) Broadcast() <-chan *FusedThreat {
@@ -79,6 +264,14 @@ func (s *CommandServer) Fusion() *FusionEngine {
 	return s.fusion
 }
 
+func (s *CommandServer) Registry() *SensorRegistry {
+	return s.registry
+}
+
+func (s *CommandServer) Dedup() *BatchDeduplicator {
+	return s.dedup
+}
+
 func (s *CommandServer) Start(port string) error {
 	lis, err := net.Listen("tcp", port)
 	if err != nil {
@@ -1,6 +1,9 @@
 package command
 
-import "math"
+import (
+	"math"
+	"time"
+)
 
 type KalmanTracker struct {
 	// State: [x, y, vx, vy]
@@ -19,6 +22,7 @@ type KalmanTracker struct {
 	R float64
 
 	initialized bool
+	last        time.Time // When the latest measurement was fused, to predict from
 }
 
 func NewKalmanTracker(processNoise, measurementNoise float64) *KalmanTracker {
@@ -91,6 +95,23 @@ func (k *KalmanTracker) Update(measuredX, measuredY float64) {
 	k.P[1][1] *= (1 - ky)
 }
 
+// UpdateVelocity folds in a measured velocity with the given noise
+// variance, replacing the guess made from position changes
+func (k *KalmanTracker) UpdateVelocity(measuredVX, measuredVY, variance float64) {
+	if !k.initialized {
+		return
+	}
+
+	kvx := k.P[2][2] / (k.P[2][2] + variance)
+	kvy := k.P[3][3] / (k.P[3][3] + variance)
+
+	k.VX += kvx * (measuredVX - k.VX)
+	k.VY += kvy * (measuredVY - k.VY)
+
+	k.P[2][2] *= (1 - kvx)
+	k.P[3][3] *= (1 - kvy)
+}
+
 func (k *KalmanTracker) GetState() (x, y, vx, vy float64) {
 	return k.X, k.Y, k.VX, k.VY
 }
@@ -0,0 +1,203 @@
+package command
+
+import (
+	"math"
+	"time"
+
+	sensorpb "distributed-sensor-fusion/shared/generated/sensorpb"
+)
+
+// sensorTrack is the latest report of one sensor's local track
+type sensorTrack struct {
+	track    *sensorpb.SensorTrack
+	received time.Time
+}
+
+type trackKey struct {
+	sensorID string
+	localID  int32
+}
+
+// ProcessTrack fuses a sensor-level track. A local track stays bound to
+// the fused threat it first joined; new local tracks are gated against
+// threats that are already built from tracks. Plots and tracks are kept in
+// separate pools since their confidences aren't comparable.
+func (f *FusionEngine) ProcessTrack(track *sensorpb.SensorTrack) *FusedThreat {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := trackKey{sensorID: track.SensorId, localID: track.LocalTrackId}
+	threat := f.threats[f.trackAssoc[key]]
+
+	if threat == nil {
+		best := f.clusterRadius
+		for _, candidate := range f.threats {
+			if len(candidate.tracks) == 0 || f.holdsOtherTrack(candidate, track) {
+				continue
+			}
+			dist := f.wrappedDistance(track.X, track.Y, candidate.X, candidate.Y)
+			if dist <= best {
+				best = dist
+				threat = candidate
+			}
+		}
+	}
+
+	if threat == nil {
+		threat = &FusedThreat{
+			ID:          f.nextID,
+			X:           track.X,
+			Y:           track.Y,
+			VX:          track.Vx,
+			VY:          track.Vy,
+			Level:       int(track.Level),
+			Confidence:  track.Confidence,
+			SensorCount: 1,
+			LastSeen:    time.Now(),
+		}
+		f.threats[f.nextID] = threat
+		f.nextID++
+	}
+	f.trackAssoc[key] = threat.ID
+
+	// One contribution per sensor; a newer local track replaces an older one
+	replaced := false
+	for i, st := range threat.tracks {
+		if st.track.SensorId == track.SensorId {
+			threat.tracks[i] = sensorTrack{track: track, received: time.Now()}
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		threat.tracks = append(threat.tracks, sensorTrack{track: track, received: time.Now()})
+	}
+
+	f.fuseTracks(threat)
+	if f.isConfirmed(threat) {
+		return threat
+	}
+	return nil
+}
+
+// holdsOtherTrack reports whether threat already has a different local
+// track from the same sensor. A sensor that resolves two tracks is telling
+// us they are two targets.
+func (f *FusionEngine) holdsOtherTrack(threat *FusedThreat, track *sensorpb.SensorTrack) bool {
+	for _, st := range threat.tracks {
+		if st.track.SensorId == track.SensorId && st.track.LocalTrackId != track.LocalTrackId {
+			return true
+		}
+	}
+	return false
+}
+
+// fuseTracks combines the sensor tracks with fast covariance intersection,
+// which stays consistent even though the tracks share process noise and
+// their cross-correlation is unknown
+func (f *FusionEngine) fuseTracks(threat *FusedThreat) {
+	// Drop contributions from sensors that stopped reporting
+	now := time.Now()
+	live := threat.tracks[:0]
+	for _, st := range threat.tracks {
+		if now.Sub(st.received) <= f.expirationTime {
+			live = append(live, st)
+		}
+	}
+	threat.tracks = live
+	if len(live) == 0 {
+		return
+	}
+
+	// Weights inversely proportional to each track's position uncertainty
+	weights := make([]float64, len(live))
+	var sumW float64
+	for i, st := range live {
+		p := positionCovariance(st.track)
+		weights[i] = 1 / math.Max(p[0][0]+p[1][1], 1e-9)
+		sumW += weights[i]
+	}
+
+	// Unwrap positions around the first track so averaging works across the seam
+	refX, refY := live[0].track.X, live[0].track.Y
+	var info [2][2]float64
+	var infoX [2]float64
+	var vx, vy, conf float64
+	var level int
+	for i, st := range live {
+		w := weights[i] / sumW
+		inv := invert2(positionCovariance(st.track))
+		x := refX + f.shortestDelta(refX, st.track.X, f.worldWidth)
+		y := refY + f.shortestDelta(refY, st.track.Y, f.worldHeight)
+
+		for r := 0; r < 2; r++ {
+			for c := 0; c < 2; c++ {
+				info[r][c] += w * inv[r][c]
+			}
+			infoX[r] += w * (inv[r][0]*x + inv[r][1]*y)
+		}
+		vx += w * st.track.Vx
+		vy += w * st.track.Vy
+		conf += st.track.Confidence
+		level += int(st.track.Level)
+	}
+
+	fused := invert2(info)
+	threat.X = f.wrap(fused[0][0]*infoX[0]+fused[0][1]*infoX[1], f.worldWidth)
+	threat.Y = f.wrap(fused[1][0]*infoX[0]+fused[1][1]*infoX[1], f.worldHeight)
+	threat.VX = vx
+	threat.VY = vy
+	threat.Confidence = conf / float64(len(live))
+	threat.Level = level / len(live)
+	threat.SensorCount = len(live)
+	threat.LastSeen = now
+}
+
+// positionCovariance extracts the 2x2 position block of a track's covariance
+func positionCovariance(t *sensorpb.SensorTrack) [2][2]float64 {
+	if len(t.Covariance) != 16 {
+		return [2][2]float64{{1, 0}, {0, 1}}
+	}
+	return [2][2]float64{
+		{t.Covariance[0], t.Covariance[1]},
+		{t.Covariance[4], t.Covariance[5]},
+	}
+}
+
+func invert2(m [2][2]float64) [2][2]float64 {
+	det := m[0][0]*m[1][1] - m[0][1]*m[1][0]
+	if math.Abs(det) < 1e-12 {
+		return [2][2]float64{{1, 0}, {0, 1}}
+	}
+	return [2][2]float64{
+		{m[1][1] / det, -m[0][1] / det},
+		{-m[1][0] / det, m[0][0] / det},
+	}
+}
+
+// shortestDelta returns to-from along one axis, the short way round on a
+// torus
+func (f *FusionEngine) shortestDelta(from, to, size float64) float64 {
+	if f.bounded {
+		return to - from
+	}
+	d := math.Mod(to-from, size)
+	if d > size/2 {
+		d -= size
+	} else if d <= -size/2 {
+		d += size
+	}
+	return d
+}
+
+// wrap maps v into [0, size), or clamps it to [0, size] in a bounded world
+func (f *FusionEngine) wrap(v, size float64) float64 {
+	if f.bounded {
+		return math.Min(math.Max(v, 0), size)
+	}
+	v = math.Mod(v, size)
+	if v < 0 {
+		v += size
+	}
+	return v
+}
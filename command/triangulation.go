@@ -0,0 +1,284 @@
+package command
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	sensorpb "distributed-sensor-fusion/shared/generated/sensorpb"
+)
+
+const (
+	bearingWindow = time.Second // How long a bearing stays usable for triangulation
+	bearingGate   = 3.0         // Standard deviations a supporting bearing may be off by
+)
+
+// fix is a candidate target position where bearings cross
+type fix struct {
+	x, y     float64
+	bearings []*sensorpb.BearingReading // One per sensor
+	cost     float64                    // Sum of squared normalised bearing residuals
+	known    bool                       // Continues an existing triangulated threat
+}
+
+// ProcessBearing adds a passive sensor's bearing and re-triangulates. It
+// returns the confirmed triangulated threats that were updated.
+func (f *FusionEngine) ProcessBearing(b *sensorpb.BearingReading) []*FusedThreat {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(time.Unix(0, b.Timestamp)) > bearingWindow {
+		return nil
+	}
+
+	// A sensor's bearings from an older scan are superseded by a newer one
+	kept := make([]*sensorpb.BearingReading, 0, len(f.bearings[b.SensorId])+1)
+	for _, old := range f.bearings[b.SensorId] {
+		if old.Timestamp >= b.Timestamp {
+			kept = append(kept, old)
+		}
+	}
+	f.bearings[b.SensorId] = append(kept, b)
+
+	updated := make([]*FusedThreat, 0)
+	claimed := make(map[int]bool)
+	for _, fx := range f.triangulate(now) {
+		threat := f.triangulatedThreat(fx, claimed)
+		if f.isConfirmed(threat) {
+			updated = append(updated, threat)
+		}
+	}
+	return updated
+}
+
+// triangulate intersects current bearings pairwise and picks out real
+// targets from ghost intersections. Every pair of bearings from different
+// sensors crosses somewhere, so N targets seen by two sensors give N²
+// candidates. Fixes are chosen greedily: most supporting sensors first,
+// then continuity with known threats, then smallest residual, and each
+// bearing may belong to one fix only. With three or more sensors
+// reporting, a fix only two of them agree on is taken as a ghost unless it
+// continues a known threat.
+func (f *FusionEngine) triangulate(now time.Time) []fix {
+	sensors := make([]string, 0, len(f.bearings))
+	for id, list := range f.bearings {
+		live := list[:0]
+		for _, b := range list {
+			if now.Sub(time.Unix(0, b.Timestamp)) <= bearingWindow {
+				live = append(live, b)
+			}
+		}
+		if len(live) == 0 {
+			delete(f.bearings, id)
+			continue
+		}
+		f.bearings[id] = live
+		sensors = append(sensors, id)
+	}
+	sort.Strings(sensors)
+	if len(sensors) < 2 {
+		return nil
+	}
+
+	candidates := make([]fix, 0)
+	for i := 0; i < len(sensors); i++ {
+		for j := i + 1; j < len(sensors); j++ {
+			for _, a := range f.bearings[sensors[i]] {
+				for _, b := range f.bearings[sensors[j]] {
+					for _, p := range f.intersect(a, b) {
+						candidates = append(candidates, f.support(p, sensors))
+					}
+				}
+			}
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		ci, cj := candidates[i], candidates[j]
+		if len(ci.bearings) != len(cj.bearings) {
+			return len(ci.bearings) > len(cj.bearings)
+		}
+		if ci.known != cj.known {
+			return ci.known
+		}
+		return ci.cost < cj.cost
+	})
+
+	used := make(map[*sensorpb.BearingReading]bool)
+	fixes := make([]fix, 0)
+	for _, c := range candidates {
+		if len(c.bearings) == 2 && len(sensors) >= 3 && !c.known {
+			continue
+		}
+		taken := false
+		for _, b := range c.bearings {
+			if used[b] {
+				taken = true
+				break
+			}
+		}
+		if taken {
+			continue
+		}
+		for _, b := range c.bearings {
+			used[b] = true
+		}
+		fixes = append(fixes, c)
+	}
+	return fixes
+}
+
+// intersect returns where two bearing rays cross. On a torus a ray wraps
+// forever, so each ray is limited to half the world in each axis around
+// its sensor, the region where shortest-path geometry holds, and the
+// second sensor is tried at each of its neighbouring images. A bounded
+// world has just the one crossing, which must lie inside it.
+func (f *FusionEngine) intersect(a, b *sensorpb.BearingReading) [][2]float64 {
+	ax, ay := math.Cos(a.Bearing), math.Sin(a.Bearing)
+	bx, by := math.Cos(b.Bearing), math.Sin(b.Bearing)
+	denom := ax*by - ay*bx
+	if math.Abs(denom) < 1e-6 {
+		return nil // Parallel
+	}
+
+	halfW, halfH := f.worldWidth/2, f.worldHeight/2
+	baseX := f.shortestDelta(a.SensorX, b.SensorX, f.worldWidth)
+	baseY := f.shortestDelta(a.SensorY, b.SensorY, f.worldHeight)
+
+	images := 1
+	if f.bounded {
+		images = 0
+	}
+
+	points := make([][2]float64, 0, 1)
+	for i := -images; i <= images; i++ {
+		for j := -images; j <= images; j++ {
+			dx := baseX + float64(i)*f.worldWidth
+			dy := baseY + float64(j)*f.worldHeight
+			t := (dx*by - dy*bx) / denom
+			r := (dx*ay - dy*ax) / denom
+			if t <= 0 || r <= 0 {
+				continue
+			}
+			if f.bounded {
+				x, y := a.SensorX+t*ax, a.SensorY+t*ay
+				if x < 0 || x > f.worldWidth || y < 0 || y > f.worldHeight {
+					continue
+				}
+			} else if math.Abs(t*ax) > halfW || math.Abs(t*ay) > halfH ||
+				math.Abs(r*bx) > halfW || math.Abs(r*by) > halfH {
+				continue
+			}
+			points = append(points, [2]float64{
+				f.wrap(a.SensorX+t*ax, f.worldWidth),
+				f.wrap(a.SensorY+t*ay, f.worldHeight),
+			})
+		}
+	}
+	return points
+}
+
+// support gathers, per sensor, the bearing that best points at p within
+// the gate, then refines p against all of them
+func (f *FusionEngine) support(p [2]float64, sensors []string) fix {
+	fx := fix{x: p[0], y: p[1]}
+	for _, id := range sensors {
+		var best *sensorpb.BearingReading
+		bestErr := math.Inf(1)
+		for _, b := range f.bearings[id] {
+			e := f.bearingResidual(b, fx.x, fx.y) / math.Max(b.StdDev, 1e-3)
+			if math.Abs(e) <= bearingGate && math.Abs(e) < bestErr {
+				best, bestErr = b, math.Abs(e)
+			}
+		}
+		if best != nil {
+			fx.bearings = append(fx.bearings, best)
+		}
+	}
+
+	fx.x, fx.y = f.refine(fx)
+	for _, b := range fx.bearings {
+		e := f.bearingResidual(b, fx.x, fx.y) / math.Max(b.StdDev, 1e-3)
+		fx.cost += e * e
+	}
+	for _, threat := range f.threats {
+		if threat.triangulated && f.wrappedDistance(fx.x, fx.y, threat.X, threat.Y) <= f.clusterRadius {
+			fx.known = true
+			break
+		}
+	}
+	return fx
+}
+
+// bearingResidual is the signed angle from a bearing to the direction of (x, y)
+func (f *FusionEngine) bearingResidual(b *sensorpb.BearingReading, x, y float64) float64 {
+	dx := f.shortestDelta(b.SensorX, x, f.worldWidth)
+	dy := f.shortestDelta(b.SensorY, y, f.worldHeight)
+	d := math.Atan2(dy, dx) - b.Bearing
+	return math.Atan2(math.Sin(d), math.Cos(d))
+}
+
+// refine finds the point closest, in the least squares sense, to all of a
+// fix's bearing lines, with sensors unwrapped around the first guess
+func (f *FusionEngine) refine(fx fix) (float64, float64) {
+	if len(fx.bearings) < 2 {
+		return fx.x, fx.y
+	}
+	var a [2][2]float64
+	var c [2]float64
+	for _, b := range fx.bearings {
+		ux, uy := math.Cos(b.Bearing), math.Sin(b.Bearing)
+		w := 1 / math.Max(b.StdDev*b.StdDev, 1e-6)
+		sx := fx.x + f.shortestDelta(fx.x, b.SensorX, f.worldWidth)
+		sy := fx.y + f.shortestDelta(fx.y, b.SensorY, f.worldHeight)
+
+		// Projection onto the line's normal: I - u u^T
+		m := [2][2]float64{{1 - ux*ux, -ux * uy}, {-ux * uy, 1 - uy*uy}}
+		for r := 0; r < 2; r++ {
+			for k := 0; k < 2; k++ {
+				a[r][k] += w * m[r][k]
+			}
+			c[r] += w * (m[r][0]*sx + m[r][1]*sy)
+		}
+	}
+	inv := invert2(a)
+	return f.wrap(inv[0][0]*c[0]+inv[0][1]*c[1], f.worldWidth),
+		f.wrap(inv[1][0]*c[0]+inv[1][1]*c[1], f.worldHeight)
+}
+
+// triangulatedThreat moves the nearest triangulated threat onto a fix, or
+// starts a new one. Triangulated threats are their own pool, like track
+// fused ones, and each takes at most one fix per pass.
+func (f *FusionEngine) triangulatedThreat(fx fix, claimed map[int]bool) *FusedThreat {
+	var threat *FusedThreat
+	best := f.clusterRadius
+	for _, candidate := range f.threats {
+		if !candidate.triangulated || claimed[candidate.ID] {
+			continue
+		}
+		if dist := f.wrappedDistance(fx.x, fx.y, candidate.X, candidate.Y); dist <= best {
+			best = dist
+			threat = candidate
+		}
+	}
+	if threat == nil {
+		threat = &FusedThreat{ID: f.nextID, triangulated: true}
+		f.threats[f.nextID] = threat
+		f.nextID++
+	}
+	claimed[threat.ID] = true
+
+	var conf float64
+	var level int
+	for _, b := range fx.bearings {
+		conf += b.Confidence
+		level += int(b.Level)
+	}
+	threat.X, threat.Y = fx.x, fx.y
+	threat.Confidence = conf / float64(len(fx.bearings))
+	threat.Level = level / len(fx.bearings)
+	threat.SensorCount = len(fx.bearings)
+	threat.LastSeen = time.Now()
+	return threat
+}
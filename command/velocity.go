@@ -0,0 +1,90 @@
+package command
+
+import (
+	"math"
+
+	sensorpb "distributed-sensor-fusion/shared/generated/sensorpb"
+)
+
+const (
+	velocityGate  = 4.0 // Standard deviations a velocity measurement may be off by
+	velocitySlack = 0.3 // Allowance for error in the threat's own velocity estimate
+)
+
+// lineOfSight returns the unit vector from where a Doppler measurement
+// was taken towards (x, y)
+func (f *FusionEngine) lineOfSight(v *sensorpb.Velocity, x, y float64) (float64, float64, bool) {
+	dx := f.shortestDelta(v.SensorX, x, f.worldWidth)
+	dy := f.shortestDelta(v.SensorY, y, f.worldHeight)
+	r := math.Sqrt(dx*dx + dy*dy)
+	if r < 1e-6 {
+		return 0, 0, false
+	}
+	return dx / r, dy / r, true
+}
+
+// estimateVelocity solves for the threat's velocity by weighted least
+// squares over its readings' velocity measurements. A full measurement
+// pins both components; a Doppler one only the component along its line
+// of sight, so two Doppler sensors at different bearings are enough.
+func (f *FusionEngine) estimateVelocity(threat *FusedThreat) {
+	var a [2][2]float64
+	var b [2]float64
+	for _, r := range threat.Readings {
+		v := r.Velocity
+		if v == nil {
+			continue
+		}
+		w := 1 / math.Max(v.StdDev*v.StdDev, 1e-6)
+		if v.Full {
+			a[0][0] += w
+			a[1][1] += w
+			b[0] += w * v.Vx
+			b[1] += w * v.Vy
+			continue
+		}
+		ux, uy, ok := f.lineOfSight(v, r.X, r.Y)
+		if !ok {
+			continue
+		}
+		a[0][0] += w * ux * ux
+		a[0][1] += w * ux * uy
+		a[1][0] += w * ux * uy
+		a[1][1] += w * uy * uy
+		b[0] += w * ux * v.Radial
+		b[1] += w * uy * v.Radial
+	}
+
+	// Near-parallel lines of sight leave one component unobserved
+	det := a[0][0]*a[1][1] - a[0][1]*a[1][0]
+	trace := a[0][0] + a[1][1]
+	if trace == 0 || det < 0.05*trace*trace {
+		threat.velocityKnown = false
+		return
+	}
+	inv := invert2(a)
+	threat.VX = inv[0][0]*b[0] + inv[0][1]*b[1]
+	threat.VY = inv[1][0]*b[0] + inv[1][1]*b[1]
+	threat.velocityKnown = true
+}
+
+// velocityConsistent gates a reading on motion: a reading whose measured
+// velocity disagrees with the threat's can't be the same target, however
+// close it is. This is what keeps crossing targets apart.
+func (f *FusionEngine) velocityConsistent(threat *FusedThreat, r *sensorpb.SensorReading) bool {
+	v := r.Velocity
+	if v == nil || !threat.velocityKnown {
+		return true
+	}
+	limit := velocityGate*v.StdDev + velocitySlack
+
+	if v.Full {
+		dx, dy := v.Vx-threat.VX, v.Vy-threat.VY
+		return math.Sqrt(dx*dx+dy*dy) <= limit*math.Sqrt2
+	}
+	ux, uy, ok := f.lineOfSight(v, threat.X, threat.Y)
+	if !ok {
+		return true
+	}
+	return math.Abs(v.Radial-(threat.VX*ux+threat.VY*uy)) <= limit
+}
@@ -77,13 +77,23 @@ func (h *WebSocketHub) BroadcastThreat(threat *FusedThreat) {
 		Sensors:    threat.SensorCount,
 	}
 
+	h.broadcastJSON(msg)
+}
+
+// BroadcastSensorEvent tells clients a sensor came up or went silent
+func (h *WebSocketHub) BroadcastSensorEvent(evt SensorEvent) {
+	h.broadcastJSON(evt)
+}
+
+func (h *WebSocketHub) broadcastJSON(msg interface{}) {
 	data, err := json.Marshal(msg)
 	if err != nil {
 		return
 	}
 
-	h.clientsMu.RLock()
-	defer h.clientsMu.RUnlock()
+	// Exclusive lock: a websocket connection allows only one writer at a time
+	h.clientsMu.Lock()
+	defer h.clientsMu.Unlock()
 
 	for client := range h.clients {
 		err := client.WriteMessage(websocket.TextMessage, data)
@@ -0,0 +1,38 @@
+package command
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"distributed-sensor-fusion/sensor"
+	worldpb "distributed-sensor-fusion/shared/generated/worldpb"
+)
+
+// FollowWorld subscribes to the world server at addr and keeps the fusion
+// engine's idea of the world's size and edges in step with it, until ctx
+// is cancelled. A snapshot load can replace the world with a different
+// one, so every state is checked rather than just the first. The tick
+// period is measured from the states' spacing, since the world's speed
+// can change at runtime.
+func (s *CommandServer) FollowWorld(ctx context.Context, addr string) error {
+	var last sensor.Geometry
+	var prev *worldpb.WorldState
+	var prevAt time.Time
+	return sensor.WatchWorld(ctx, addr, func(state *worldpb.WorldState) {
+		now := time.Now()
+		if prev != nil && state.Epoch == prev.Epoch && state.Tick > prev.Tick {
+			period := float64(now.Sub(prevAt)) / float64(state.Tick-prev.Tick)
+			s.tickPeriod.Store(int64(0.8*float64(s.tickPeriod.Load()) + 0.2*period))
+		}
+		prev, prevAt = state, now
+
+		g := sensor.GeometryOf(state)
+		if g == last {
+			return
+		}
+		last = g
+		s.fusion.SetWorld(g.Width, g.Height, g.Bounded)
+		log.Printf("World is %.0fx%.0f, bounded=%v", g.Width, g.Height, g.Bounded)
+	}, nil)
+}
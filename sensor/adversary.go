@@ -0,0 +1,217 @@
+package sensor
+
+import (
+	"flag"
+	"math/rand"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	sensorpb "distributed-sensor-fusion/shared/generated/sensorpb"
+)
+
+const (
+	OriginPhantom ReadingOrigin = "phantom" // Invented by an adversarial sensor
+	OriginReplay  ReadingOrigin = "replay"  // Old detection resent as new
+)
+
+// AdversaryConfig turns a sensor into a compromised or faulty one for
+// robustness testing. Every behaviour is off at its zero value, so they
+// can be combined freely.
+type AdversaryConfig struct {
+	SpoofX        float64 `json:"spoof_x"` // Fixed offset added to every target report
+	SpoofY        float64 `json:"spoof_y"`
+	Phantoms      int     `json:"phantoms"`       // Invented targets that move plausibly
+	ReplayDelay   float64 `json:"replay_delay"`   // Seconds; resend target reports this old as current
+	Impersonate   string  `json:"impersonate"`    // SensorId to claim instead of our own
+	MaxConfidence bool    `json:"max_confidence"` // Report confidence 1 for everything
+}
+
+// RegisterFlags binds the behaviours to command line flags
+func (a *AdversaryConfig) RegisterFlags(fs *flag.FlagSet) {
+	fs.Float64Var(&a.SpoofX, "spoof-x", a.SpoofX, "adversary: add this X offset to every target report")
+	fs.Float64Var(&a.SpoofY, "spoof-y", a.SpoofY, "adversary: add this Y offset to every target report")
+	fs.IntVar(&a.Phantoms, "phantoms", a.Phantoms, "adversary: number of invented targets to report")
+	fs.Float64Var(&a.ReplayDelay, "replay-delay", a.ReplayDelay, "adversary: replay target reports this many seconds old")
+	fs.StringVar(&a.Impersonate, "impersonate", a.Impersonate, "adversary: claim this sensor ID in reports")
+	fs.BoolVar(&a.MaxConfidence, "max-confidence", a.MaxConfidence, "adversary: report confidence 1 for everything")
+}
+
+// Enabled reports whether any behaviour is switched on
+func (a AdversaryConfig) Enabled() bool {
+	return a.SpoofX != 0 || a.SpoofY != 0 || a.Phantoms > 0 || a.ReplayDelay > 0 ||
+		a.Impersonate != "" || a.MaxConfidence
+}
+
+// phantom is an invented target. Velocities are per scan, like the world's.
+type phantom struct {
+	id     int32
+	x, y   float64
+	vx, vy float64
+	level  int32
+}
+
+type pastScan struct {
+	at         int64
+	detections []detection
+}
+
+// adversary tampers with a sensor's scans before they are tracked or sent
+type adversary struct {
+	sensorID string
+	cfg      AdversaryConfig
+	phantoms []*phantom
+	history  []pastScan
+}
+
+func newAdversary(sensorID string, cfg AdversaryConfig) *adversary {
+	return &adversary{sensorID: sensorID, cfg: cfg}
+}
+
+// apply rewrites one scan's detections. Truth records keep the real
+// positions and mark invented or replayed reports by their origin.
+func (a *adversary) apply(detections []detection, now int64, cov Coverage, g Geometry) []detection {
+	if a.cfg.ReplayDelay > 0 {
+		detections = a.replay(detections, now)
+	}
+
+	if a.cfg.SpoofX != 0 || a.cfg.SpoofY != 0 {
+		for i := range detections {
+			d := &detections[i]
+			if d.truth.Origin == OriginClutter {
+				continue
+			}
+			d.reading.X = g.wrap(d.reading.X+a.cfg.SpoofX, g.Width)
+			d.reading.Y = g.wrap(d.reading.Y+a.cfg.SpoofY, g.Height)
+			d.truth.X, d.truth.Y = d.reading.X, d.reading.Y
+			d.spoofX, d.spoofY = a.cfg.SpoofX, a.cfg.SpoofY
+		}
+	}
+
+	if a.cfg.Phantoms > 0 {
+		detections = append(detections, a.movePhantoms(now, cov, g)...)
+	}
+
+	for _, d := range detections {
+		if a.cfg.Impersonate != "" {
+			d.reading.SensorId = a.cfg.Impersonate
+		}
+		if a.cfg.MaxConfidence {
+			d.reading.Confidence = 1.0
+		}
+	}
+	return detections
+}
+
+// tamperTrack applies the behaviours that make sense for a track report
+func (a *adversary) tamperTrack(track *sensorpb.SensorTrack) {
+	if a.cfg.Impersonate != "" {
+		track.SensorId = a.cfg.Impersonate
+	}
+	if a.cfg.MaxConfidence {
+		track.Confidence = 1.0
+	}
+}
+
+// replay swaps the scan's target reports for those of the newest scan at
+// least ReplayDelay old, restamped as current. Until the history is that
+// deep the live reports go out unchanged.
+func (a *adversary) replay(detections []detection, now int64) []detection {
+	live := make([]detection, 0, len(detections))
+	targets := make([]detection, 0, len(detections))
+	for _, d := range detections {
+		if d.truth.Origin == OriginTarget {
+			copied := d
+			copied.reading = proto.Clone(d.reading).(*sensorpb.SensorReading)
+			targets = append(targets, copied)
+		} else {
+			live = append(live, d)
+		}
+	}
+	a.history = append(a.history, pastScan{at: now, detections: targets})
+
+	cutoff := now - int64(a.cfg.ReplayDelay*float64(time.Second))
+	idx := -1
+	for i, scan := range a.history {
+		if scan.at <= cutoff {
+			idx = i
+		}
+	}
+	if idx < 0 {
+		return detections
+	}
+	old := a.history[idx]
+	a.history = a.history[idx+1:]
+
+	for _, d := range old.detections {
+		d.reading.Timestamp = now
+		d.truth.Timestamp = now
+		d.truth.Origin = OriginReplay
+		live = append(live, d)
+	}
+	return live
+}
+
+// movePhantoms advances the invented targets and reports each one. They
+// start inside coverage and wander with small velocity changes.
+func (a *adversary) movePhantoms(now int64, cov Coverage, g Geometry) []detection {
+	for len(a.phantoms) < a.cfg.Phantoms {
+		x, y := cov.samplePoint(g)
+		a.phantoms = append(a.phantoms, &phantom{
+			id:    int32(-100 - len(a.phantoms)),
+			x:     x,
+			y:     y,
+			vx:    rand.Float64()*4 - 2,
+			vy:    rand.Float64()*4 - 2,
+			level: int32(rand.Intn(10) + 1),
+		})
+	}
+
+	out := make([]detection, 0, len(a.phantoms))
+	for _, p := range a.phantoms {
+		p.vx = clamp(p.vx+Gaussian(0, 0.1), -2, 2)
+		p.vy = clamp(p.vy+Gaussian(0, 0.1), -2, 2)
+		var flipX, flipY bool
+		p.x, flipX = g.bounce(p.x, p.vx, g.Width)
+		p.y, flipY = g.bounce(p.y, p.vy, g.Height)
+		if flipX {
+			p.vx = -p.vx
+		}
+		if flipY {
+			p.vy = -p.vy
+		}
+
+		out = append(out, detection{
+			reading: &sensorpb.SensorReading{
+				SensorId:   a.sensorID,
+				ThreatId:   -1,
+				X:          p.x,
+				Y:          p.y,
+				Level:      p.level,
+				Timestamp:  now,
+				Confidence: 0.7 + rand.Float64()*0.3,
+			},
+			truth: TruthRecord{
+				SensorID:  a.sensorID,
+				Timestamp: now,
+				Origin:    OriginPhantom,
+				ThreatID:  p.id,
+				X:         p.x,
+				Y:         p.y,
+				TrueX:     p.x,
+				TrueY:     p.y,
+			},
+		})
+	}
+	return out
+}
+
+func clamp(v, lo, hi float64) float64 {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
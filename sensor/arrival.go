@@ -0,0 +1,77 @@
+package sensor
+
+import (
+	"log"
+	"time"
+
+	sensorpb "distributed-sensor-fusion/shared/generated/sensorpb"
+)
+
+// SoundSpeed is how fast acoustic emissions travel, in world units per second
+const SoundSpeed = 343.0
+
+// EnableTDOA makes the sensor one element of an acoustic array: it reports
+// only when each emission reaches it, on Arrivals(). Sources emit once per
+// world state, so the sensor must scan every state as it arrives. The
+// timing noise is NoiseConfig.TimingStdDev. Call before Start.
+func (s *Sensor) EnableTDOA() {
+	s.arrivals = make(chan *sensorpb.ArrivalReading, 100)
+}
+
+// Arrivals returns arrival time reports. It is nil unless the sensor is in TDOA mode.
+func (s *Sensor) Arrivals() <-chan *sensorpb.ArrivalReading {
+	return s.arrivals
+}
+
+// publishArrivals reports when each detected source's emission reaches the
+// sensor, on the sensor's clock. Sources emit as the world reaches the tick,
+// at the state's timestamp, so arrivals don't depend on how long the state
+// took to get here. Clutter makes no sound to time. Each report is sent
+// once the sound has actually arrived.
+//
+// The signature is the source's true threat ID, a ground truth shortcut.
+// The command server relies on it to group one emission's arrivals, as the
+// fused threat's identity from one emission to the next, and to choose
+// between ambiguous fixes. A real array would have only waveform
+// correlation to go on, so TDOA fusion here is better than it could be.
+func (s *Sensor) publishArrivals(tick int32, emittedAt int64, detections []detection, g Geometry) {
+	emitted := time.Unix(0, emittedAt)
+	if emittedAt == 0 {
+		emitted = time.Now() // Older world servers don't stamp their states
+	}
+	for _, d := range detections {
+		if d.reading.ThreatId < 0 {
+			continue
+		}
+		r := g.distance(s.Coverage.X, s.Coverage.Y, d.truth.TrueX, d.truth.TrueY)
+		arrived := emitted.Add(time.Duration(r / SoundSpeed * float64(time.Second)))
+		arrival := s.clockAt(arrived) + int64(Gaussian(0, s.NoiseConfig.TimingStdDev)*float64(time.Second))
+
+		a := &sensorpb.ArrivalReading{
+			SensorId:    d.reading.SensorId,
+			SensorX:     s.Coverage.X,
+			SensorY:     s.Coverage.Y,
+			Pulse:       tick,
+			Signature:   d.reading.ThreatId,
+			ArrivalTime: arrival,
+			StdDev:      s.NoiseConfig.TimingStdDev,
+			Speed:       SoundSpeed,
+			Level:       d.reading.Level,
+			Timestamp:   arrival,
+			Confidence:  d.reading.Confidence,
+		}
+		truth := d.truth
+		truth.Arrival = arrival
+
+		time.AfterFunc(time.Until(arrived), func() {
+			s.deliver(func() {
+				select {
+				case s.arrivals <- a:
+					s.emitTruth(truth)
+				default:
+					log.Printf("[%s] Arrival channel full, dropping", s.ID)
+				}
+			})
+		})
+	}
+}
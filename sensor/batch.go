@@ -0,0 +1,387 @@
+package sensor
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math/rand"
+	"sync"
+	"time"
+
+	sensorpb "distributed-sensor-fusion/shared/generated/sensorpb"
+)
+
+// errDrained ends a batch stream once a closed sender has nothing unacked
+var errDrained = errors.New("closed with everything acknowledged")
+
+// batchSender delivers readings as acknowledged batches. Readings wait in
+// pending until batched; batches then sit in inflight until the server
+// acks their sequence number, and are retransmitted after a reconnect.
+type batchSender struct {
+	name      string
+	opts      ClientOptions
+	queueFile string
+	client    sensorpb.SensorServiceClient
+	session   uint64
+
+	mu          sync.Mutex
+	cond        *sync.Cond
+	pending     []queued[*sensorpb.SensorReading]
+	inflight    []*inflightBatch
+	nextSeq     map[string]uint64
+	state       ConnState
+	used        bool
+	dropped     int64
+	reconnects  int64
+	acked       int64
+	retransmits int64
+	closed      bool
+	done        chan struct{}
+}
+
+type inflightBatch struct {
+	batch *sensorpb.ReadingBatch
+	first time.Time // Oldest reading's enqueue time, for MaxAge
+}
+
+func newBatchSender(name string, opts ClientOptions, queueFile string, client sensorpb.SensorServiceClient) *batchSender {
+	b := &batchSender{
+		name:      name,
+		opts:      opts,
+		queueFile: queueFile,
+		client:    client,
+		session:   rand.Uint64(),
+		nextSeq:   make(map[string]uint64),
+		state:     StateConnecting,
+		done:      make(chan struct{}),
+	}
+	if b.opts.MaxBatch <= 0 {
+		b.opts.MaxBatch = 1
+	}
+	if b.opts.MaxInflight <= 0 {
+		b.opts.MaxInflight = 1
+	}
+	b.cond = sync.NewCond(&b.mu)
+	return b
+}
+
+func (b *batchSender) start(ctx context.Context) {
+	if b.queueFile != "" {
+		b.load()
+	}
+
+	go func() {
+		<-ctx.Done()
+		b.mu.Lock()
+		b.cond.Broadcast()
+		b.mu.Unlock()
+	}()
+	go b.run(ctx)
+}
+
+func (b *batchSender) push(reading *sensorpb.SensorReading) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return ErrClientClosed
+	}
+
+	if b.opts.MaxQueue > 0 && len(b.pending) >= b.opts.MaxQueue {
+		b.pending = b.pending[1:]
+		b.dropped++
+	}
+	b.pending = append(b.pending, queued[*sensorpb.SensorReading]{msg: reading, enqueued: time.Now()})
+	b.used = true
+	b.cond.Broadcast()
+	return nil
+}
+
+// close refuses further readings. The sender exits once everything
+// queued has been acknowledged.
+func (b *batchSender) close() {
+	b.mu.Lock()
+	b.closed = true
+	b.cond.Broadcast()
+	b.mu.Unlock()
+}
+
+func (b *batchSender) stop() {
+	b.close()
+	<-b.done
+
+	if b.queueFile != "" {
+		b.save()
+	}
+}
+
+func (b *batchSender) status() ClientStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	unacked := 0
+	for _, ib := range b.inflight {
+		unacked += len(ib.batch.Readings)
+	}
+	st := ClientStatus{
+		QueueDepth:  len(b.pending) + unacked,
+		Unacked:     unacked,
+		Dropped:     b.dropped,
+		Reconnects:  b.reconnects,
+		Acked:       b.acked,
+		Retransmits: b.retransmits,
+	}
+	if b.used {
+		st.State = b.state
+	}
+	return st
+}
+
+func (b *batchSender) setState(state ConnState) {
+	b.mu.Lock()
+	b.state = state
+	b.mu.Unlock()
+}
+
+// run keeps a batch stream open until ctx ends, reconnecting with backoff
+func (b *batchSender) run(ctx context.Context) {
+	defer close(b.done)
+	defer b.setState(StateClosed)
+
+	backoff := b.opts.Backoff
+	first := true
+
+	for ctx.Err() == nil {
+		b.mu.Lock()
+		for b.idleLocked() && !b.closed && ctx.Err() == nil {
+			b.cond.Wait()
+		}
+		drained := b.idleLocked()
+		b.mu.Unlock()
+		if ctx.Err() != nil || drained {
+			return
+		}
+
+		b.setState(StateConnecting)
+		err := b.runStream(ctx, func() {
+			b.mu.Lock()
+			b.state = StateConnected
+			if !first {
+				b.reconnects++
+			}
+			b.mu.Unlock()
+			first = false
+			backoff.Reset()
+			log.Printf("Opened %s stream", b.name)
+		})
+		if ctx.Err() != nil || errors.Is(err, errDrained) {
+			return
+		}
+
+		b.setState(StateDisconnected)
+		delay := backoff.Next()
+		log.Printf("%s stream lost (%v), retrying in %v", b.name, err, delay)
+		if !sleepCtx(ctx, delay) {
+			return
+		}
+	}
+}
+
+// runStream runs one stream: retransmit everything unacked, then batch and
+// send new readings while a receiver goroutine retires acked batches
+func (b *batchSender) runStream(ctx context.Context, onOpen func()) error {
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := b.client.StreamReadingBatches(streamCtx)
+	if err != nil {
+		return err
+	}
+	onOpen()
+
+	recvErr := make(chan error, 1)
+	go func() {
+		for {
+			ack, err := stream.Recv()
+			if err != nil {
+				recvErr <- err
+				b.mu.Lock()
+				b.cond.Broadcast()
+				b.mu.Unlock()
+				return
+			}
+			b.retire(ack)
+		}
+	}()
+
+	b.mu.Lock()
+	b.dropExpiredLocked()
+	resend := make([]*sensorpb.ReadingBatch, len(b.inflight))
+	for i, ib := range b.inflight {
+		resend[i] = ib.batch
+	}
+	b.retransmits += int64(len(resend))
+	b.mu.Unlock()
+
+	for _, batch := range resend {
+		if err := stream.Send(batch); err != nil {
+			return err
+		}
+	}
+
+	for {
+		batches, err := b.nextBatches(streamCtx, recvErr)
+		if err != nil {
+			stream.CloseSend()
+			return err
+		}
+		for _, batch := range batches {
+			if err := stream.Send(batch); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// nextBatches waits for pending readings and window space, then moves
+// pending readings into new inflight batches, one sensor per batch
+func (b *batchSender) nextBatches(ctx context.Context, recvErr chan error) ([]*sensorpb.ReadingBatch, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for {
+		select {
+		case err := <-recvErr:
+			return nil, err
+		default:
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		b.dropExpiredLocked()
+		if len(b.pending) > 0 && len(b.inflight) < b.opts.MaxInflight {
+			break
+		}
+		if b.closed && b.idleLocked() {
+			return nil, errDrained
+		}
+		b.cond.Wait()
+	}
+
+	batches := make([]*sensorpb.ReadingBatch, 0)
+	open := make(map[string]*inflightBatch)
+	rest := b.pending[:0]
+	for _, q := range b.pending {
+		sensorID := q.msg.SensorId
+		ib := open[sensorID]
+		if ib == nil || len(ib.batch.Readings) >= b.opts.MaxBatch {
+			if len(b.inflight) >= b.opts.MaxInflight {
+				rest = append(rest, q)
+				continue
+			}
+			b.nextSeq[sensorID]++
+			ib = &inflightBatch{
+				batch: &sensorpb.ReadingBatch{
+					SensorId:  sensorID,
+					SessionId: b.session,
+					Sequence:  b.nextSeq[sensorID],
+				},
+				first: q.enqueued,
+			}
+			open[sensorID] = ib
+			b.inflight = append(b.inflight, ib)
+			batches = append(batches, ib.batch)
+		}
+		ib.batch.Readings = append(ib.batch.Readings, q.msg)
+	}
+	b.pending = rest
+	return batches, nil
+}
+
+// idleLocked reports whether there is nothing pending or unacked
+func (b *batchSender) idleLocked() bool {
+	return len(b.pending) == 0 && len(b.inflight) == 0
+}
+
+// retire drops an acknowledged batch from the inflight window
+func (b *batchSender) retire(ack *sensorpb.BatchAck) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for i, ib := range b.inflight {
+		if ib.batch.SensorId == ack.SensorId && ib.batch.Sequence == ack.Sequence {
+			b.inflight = append(b.inflight[:i], b.inflight[i+1:]...)
+			b.acked++
+			b.cond.Broadcast()
+			return
+		}
+	}
+}
+
+// dropExpiredLocked discards pending readings and unacked batches older than MaxAge
+func (b *batchSender) dropExpiredLocked() {
+	if b.opts.MaxAge <= 0 {
+		return
+	}
+	cutoff := time.Now().Add(-b.opts.MaxAge)
+
+	i := 0
+	for i < len(b.pending) && b.pending[i].enqueued.Before(cutoff) {
+		i++
+	}
+	if i > 0 {
+		b.dropped += int64(i)
+		b.pending = b.pending[i:]
+	}
+
+	live := b.inflight[:0]
+	for _, ib := range b.inflight {
+		if ib.first.Before(cutoff) {
+			b.dropped += int64(len(ib.batch.Readings))
+			continue
+		}
+		live = append(live, ib)
+	}
+	b.inflight = live
+}
+
+// save persists unacked and pending readings, oldest first
+func (b *batchSender) save() {
+	b.mu.Lock()
+	readings := make([]*sensorpb.SensorReading, 0)
+	for _, ib := range b.inflight {
+		readings = append(readings, ib.batch.Readings...)
+	}
+	for _, q := range b.pending {
+		readings = append(readings, q.msg)
+	}
+	b.mu.Unlock()
+
+	if err := saveMessages(b.queueFile, b.name, readings); err != nil {
+		log.Printf("Could not persist %s queue to %s: %v", b.name, b.queueFile, err)
+	}
+}
+
+// load restores readings from a previous run into pending. They are
+// re-batched under this run's session, with age taken from their timestamps.
+func (b *batchSender) load() {
+	readings, err := loadMessages(b.queueFile, b.name, func() *sensorpb.SensorReading {
+		return &sensorpb.SensorReading{}
+	})
+	if err != nil {
+		log.Printf("Could not restore %s queue from %s: %v", b.name, b.queueFile, err)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, r := range readings {
+		b.pending = append(b.pending, queued[*sensorpb.SensorReading]{msg: r, enqueued: time.Unix(0, r.Timestamp)})
+	}
+	if b.opts.MaxQueue > 0 && len(b.pending) > b.opts.MaxQueue {
+		b.dropped += int64(len(b.pending) - b.opts.MaxQueue)
+		b.pending = b.pending[len(b.pending)-b.opts.MaxQueue:]
+	}
+	if len(b.pending) > 0 {
+		b.used = true
+	}
+}
@@ -0,0 +1,61 @@
+package sensor
+
+import (
+	"log"
+	"math"
+
+	sensorpb "distributed-sensor-fusion/shared/generated/sensorpb"
+)
+
+// EnableBearingOnly makes the sensor passive: it reports only the direction
+// to each detection, on Bearings(), instead of positions on Readings().
+// The noise is NoiseConfig.BearingStdDev. Call before Start.
+func (s *Sensor) EnableBearingOnly() {
+	s.bearings = make(chan *sensorpb.BearingReading, 100)
+}
+
+// Bearings returns bearing reports. It is nil unless the sensor is bearing-only.
+func (s *Sensor) Bearings() <-chan *sensorpb.BearingReading {
+	return s.bearings
+}
+
+// publishBearings turns a scan's detections into bearings from the sensor.
+// Directions come from the true source position with angular noise, since
+// a passive sensor never had a position to add noise to. A spoofing
+// adversary's offset moves the source first, so a compromised passive
+// sensor points where a compromised active one would report.
+func (s *Sensor) publishBearings(now int64, detections []detection, g Geometry) {
+	for _, d := range detections {
+		x := g.wrap(d.truth.TrueX+d.spoofX, g.Width)
+		y := g.wrap(d.truth.TrueY+d.spoofY, g.Height)
+		dx := g.shortestDelta(s.Coverage.X, x, g.Width)
+		dy := g.shortestDelta(s.Coverage.Y, y, g.Height)
+		if dx == 0 && dy == 0 {
+			continue
+		}
+		bearing := math.Atan2(dy, dx) + Gaussian(0, s.NoiseConfig.BearingStdDev)
+
+		b := &sensorpb.BearingReading{
+			SensorId:   d.reading.SensorId,
+			ThreatId:   d.reading.ThreatId,
+			SensorX:    s.Coverage.X,
+			SensorY:    s.Coverage.Y,
+			Bearing:    math.Atan2(math.Sin(bearing), math.Cos(bearing)),
+			StdDev:     s.NoiseConfig.BearingStdDev,
+			Level:      d.reading.Level,
+			Timestamp:  now,
+			Confidence: d.reading.Confidence,
+		}
+		truth := d.truth
+		truth.Bearing = b.Bearing
+
+		s.deliver(func() {
+			select {
+			case s.bearings <- b:
+				s.emitTruth(truth)
+			default:
+				log.Printf("[%s] Bearing channel full, dropping", s.ID)
+			}
+		})
+	}
+}
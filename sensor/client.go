@@ -2,7 +2,9 @@ package sensor
 
 import (
 	"context"
+	"errors"
 	"log"
+	"time"
 
 	sensorpb "distributed-sensor-fusion/shared/generated/sensorpb"
 
@@ -10,44 +12,223 @@ import (
 	"google.golang.org/grpc/credentials/insecure"
 )
 
+var ErrClientClosed = errors.New("command client closed")
+
+// ClientOptions controls reconnection and store-and-forward behaviour
+type ClientOptions struct {
+	Backoff     Backoff
+	MaxQueue    int           // Messages held while disconnected, oldest dropped first
+	MaxAge      time.Duration // Queued messages older than this are discarded, 0 keeps all
+	QueueFile   string        // If set, unsent messages survive restarts in this file
+	MaxBatch    int           // Readings per batch
+	MaxInflight int           // Unacknowledged batches allowed before sending pauses
+	DrainTime   time.Duration // How long Close waits for queued messages to be delivered, 0 not at all
+}
+
+func DefaultClientOptions() ClientOptions {
+	return ClientOptions{
+		Backoff:     DefaultBackoff(),
+		MaxQueue:    1000,
+		MaxAge:      5 * time.Second,
+		MaxBatch:    50,
+		MaxInflight: 32,
+		DrainTime:   2 * time.Second,
+	}
+}
+
+// ClientStatus is a monitoring snapshot of a CommandClient
+type ClientStatus struct {
+	State       ConnState
+	QueueDepth  int   // Messages not yet delivered, including unacked ones
+	Unacked     int   // Readings sent in batches the server hasn't acked
+	Dropped     int64 // Messages discarded for queue overflow or age
+	Reconnects  int64
+	Acked       int64 // Reading batches acknowledged
+	Retransmits int64 // Reading batches resent after a reconnect
+}
+
+// CommandClient streams readings, local tracks, bearings and arrival times
+// to the command server. Send, SendTrack, SendBearing and SendArrival only
+// queue. Readings travel in acknowledged batches; the rest use plain client
+// streams. Each reconnects independently.
 type CommandClient struct {
-	addr   string
-	conn   *grpc.ClientConn
-	stream sensorpb.SensorService_StreamReadingsClient
+	addr string
+	opts ClientOptions
+	conn *grpc.ClientConn
+
+	readings *batchSender
+	tracks   *outbox[*sensorpb.SensorTrack]
+	bearings *outbox[*sensorpb.BearingReading]
+	arrivals *outbox[*sensorpb.ArrivalReading]
+
+	cancel context.CancelFunc
 }
 
 func NewCommandClient(addr string) *CommandClient {
-	return &CommandClient{addr: addr}
+	return NewCommandClientWithOptions(addr, DefaultClientOptions())
 }
 
+func NewCommandClientWithOptions(addr string, opts ClientOptions) *CommandClient {
+	return &CommandClient{addr: addr, opts: opts}
+}
+
+// Connect starts the background senders. It doesn't wait for the command
+// server to be reachable; messages queue up until it is. The senders
+// outlive ctx so that Close can still deliver what is queued.
 func (c *CommandClient) Connect(ctx context.Context) error {
 	conn, err := grpc.Dial(c.addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	if err != nil {
 		return err
 	}
 	c.conn = conn
-
 	client := sensorpb.NewSensorServiceClient(conn)
-	stream, err := client.StreamReadings(ctx)
-	if err != nil {
-		return err
+
+	trackFile, bearingFile, arrivalFile := "", "", ""
+	if c.opts.QueueFile != "" {
+		trackFile = c.opts.QueueFile + ".tracks"
+		bearingFile = c.opts.QueueFile + ".bearings"
+		arrivalFile = c.opts.QueueFile + ".arrivals"
 	}
-	c.stream = stream
 
-	log.Printf("Connected to command server at %s", c.addr)
+	c.readings = newBatchSender("readings to "+c.addr, c.opts, c.opts.QueueFile, client)
+	c.tracks = newOutbox("tracks to "+c.addr, c.opts, trackFile,
+		func(ctx context.Context) (clientStream[*sensorpb.SensorTrack], error) {
+			return client.StreamTracks(ctx)
+		},
+		func() *sensorpb.SensorTrack { return &sensorpb.SensorTrack{} },
+		func(t *sensorpb.SensorTrack) int64 { return t.Timestamp })
+	c.bearings = newOutbox("bearings to "+c.addr, c.opts, bearingFile,
+		func(ctx context.Context) (clientStream[*sensorpb.BearingReading], error) {
+			return client.StreamBearings(ctx)
+		},
+		func() *sensorpb.BearingReading { return &sensorpb.BearingReading{} },
+		func(b *sensorpb.BearingReading) int64 { return b.Timestamp })
+	c.arrivals = newOutbox("arrivals to "+c.addr, c.opts, arrivalFile,
+		func(ctx context.Context) (clientStream[*sensorpb.ArrivalReading], error) {
+			return client.StreamArrivals(ctx)
+		},
+		func() *sensorpb.ArrivalReading { return &sensorpb.ArrivalReading{} },
+		func(a *sensorpb.ArrivalReading) int64 { return a.Timestamp })
+
+	ctx, c.cancel = context.WithCancel(context.WithoutCancel(ctx))
+	c.readings.start(ctx)
+	c.tracks.start(ctx)
+	c.bearings.start(ctx)
+	c.arrivals.start(ctx)
+
 	return nil
 }
 
+// Send queues a reading for delivery
 func (c *CommandClient) Send(reading *sensorpb.SensorReading) error {
-	return c.stream.Send(reading)
+	if c.readings == nil {
+		return ErrClientClosed
+	}
+	return c.readings.push(reading)
 }
 
+// SendTrack queues a local track report for delivery
+func (c *CommandClient) SendTrack(track *sensorpb.SensorTrack) error {
+	if c.tracks == nil {
+		return ErrClientClosed
+	}
+	return c.tracks.push(track)
+}
+
+// SendBearing queues a passive sensor's bearing for delivery
+func (c *CommandClient) SendBearing(bearing *sensorpb.BearingReading) error {
+	if c.bearings == nil {
+		return ErrClientClosed
+	}
+	return c.bearings.push(bearing)
+}
+
+// SendArrival queues an acoustic sensor's arrival time for delivery
+func (c *CommandClient) SendArrival(arrival *sensorpb.ArrivalReading) error {
+	if c.arrivals == nil {
+		return ErrClientClosed
+	}
+	return c.arrivals.push(arrival)
+}
+
+// Status reports connection state and queue depth for monitoring. State
+// is the worst of the streams actually in use.
+func (c *CommandClient) Status() ClientStatus {
+	status := ClientStatus{State: StateConnecting}
+	if c.readings == nil {
+		return status
+	}
+
+	active := 0
+	for _, st := range []ClientStatus{c.readings.status(), c.tracks.status(), c.bearings.status(), c.arrivals.status()} {
+		status.QueueDepth += st.QueueDepth
+		status.Unacked += st.Unacked
+		status.Dropped += st.Dropped
+		status.Reconnects += st.Reconnects
+		status.Acked += st.Acked
+		status.Retransmits += st.Retransmits
+		if st.State == "" {
+			continue // Never used
+		}
+		if active == 0 || connStateRank(st.State) > connStateRank(status.State) {
+			status.State = st.State
+		}
+		active++
+	}
+	return status
+}
+
+// Close delivers what is queued, giving up after DrainTime, and shuts the
+// streams down. Anything still undelivered is persisted if QueueFile is
+// set and lost otherwise.
 func (c *CommandClient) Close() error {
-	if c.stream != nil {
-		c.stream.CloseAndRecv()
+	if c.cancel != nil {
+		c.readings.close()
+		c.tracks.close()
+		c.bearings.close()
+		c.arrivals.close()
+		if c.opts.DrainTime > 0 && !waitDone(c.opts.DrainTime, c.readings.done, c.tracks.done, c.bearings.done, c.arrivals.done) {
+			log.Printf("Gave up delivering queued messages to %s after %v", c.addr, c.opts.DrainTime)
+		}
+
+		c.cancel()
+		c.readings.stop()
+		c.tracks.stop()
+		c.bearings.stop()
+		c.arrivals.stop()
 	}
+
 	if c.conn != nil {
 		return c.conn.Close()
 	}
 	return nil
 }
+
+// waitDone waits up to timeout for every channel to be closed, reporting
+// whether they all were
+func waitDone(timeout time.Duration, done ...chan struct{}) bool {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for _, ch := range done {
+		select {
+		case <-ch:
+		case <-timer.C:
+			return false
+		}
+	}
+	return true
+}
+
+// connStateRank orders states from healthy to unhealthy
+func connStateRank(s ConnState) int {
+	switch s {
+	case StateConnected:
+		return 0
+	case StateConnecting:
+		return 1
+	case StateDisconnected:
+		return 2
+	default:
+		return 3
+	}
+}
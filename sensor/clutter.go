@@ -0,0 +1,131 @@
+package sensor
+
+import (
+	"math"
+	"math/rand"
+)
+
+// ClutterHotSpot raises the false alarm density around a point, e.g. a
+// coastline or urban area that produces more spurious returns
+type ClutterHotSpot struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Radius float64 `json:"radius"` // Standard deviation of the spot's spread
+	Rate   float64 `json:"rate"`   // Expected extra false alarms per scan from this spot
+}
+
+// Coverage is the area a sensor can observe, centred on the sensor itself.
+// A zero Radius means the whole world.
+type Coverage struct {
+	X      float64
+	Y      float64
+	Radius float64
+}
+
+// Contains reports whether (x, y) is inside the coverage area
+func (c Coverage) Contains(x, y float64, g Geometry) bool {
+	if c.Radius <= 0 {
+		return true
+	}
+	return g.distance(c.X, c.Y, x, y) <= c.Radius
+}
+
+// Area returns the coverage area, clipped to the world size. In a bounded
+// world the part of the circle beyond the edges is cut off.
+func (c Coverage) Area(g Geometry) float64 {
+	if c.Radius <= 0 {
+		return g.Width * g.Height
+	}
+	if !g.Bounded {
+		return math.Min(math.Pi*c.Radius*c.Radius, g.Width*g.Height)
+	}
+
+	// Integrate the clipped chord height in strips across the circle
+	const strips = 200
+	x0, x1 := math.Max(c.X-c.Radius, 0), math.Min(c.X+c.Radius, g.Width)
+	if x1 <= x0 {
+		return 0
+	}
+	w := (x1 - x0) / strips
+	area := 0.0
+	for i := 0; i < strips; i++ {
+		dx := x0 + (float64(i)+0.5)*w - c.X
+		half := math.Sqrt(math.Max(c.Radius*c.Radius-dx*dx, 0))
+		area += w * math.Max(math.Min(c.Y+half, g.Height)-math.Max(c.Y-half, 0), 0)
+	}
+	return area
+}
+
+// ClutterPoint is a single false alarm position
+type ClutterPoint struct {
+	X float64
+	Y float64
+}
+
+// GenerateClutter draws a Poisson number of false alarms for one scan.
+// FalsePositiveRate is the expected background count over the whole world;
+// it is scaled by the fraction of the world the sensor covers, and each
+// hot spot inside the coverage adds its own Poisson contribution.
+func (nc NoiseConfig) GenerateClutter(cov Coverage, g Geometry) []ClutterPoint {
+	points := make([]ClutterPoint, 0)
+
+	background := nc.FalsePositiveRate * cov.Area(g) / (g.Width * g.Height)
+	for n := Poisson(background); n > 0; n-- {
+		x, y := cov.samplePoint(g)
+		points = append(points, ClutterPoint{X: x, Y: y})
+	}
+
+	for _, spot := range nc.ClutterHotSpots {
+		for n := Poisson(spot.Rate); n > 0; n-- {
+			x := g.wrap(Gaussian(spot.X, spot.Radius), g.Width)
+			y := g.wrap(Gaussian(spot.Y, spot.Radius), g.Height)
+			if g.inside(x, y) && cov.Contains(x, y, g) {
+				points = append(points, ClutterPoint{X: x, Y: y})
+			}
+		}
+	}
+
+	return points
+}
+
+// samplePoint draws a uniformly distributed point inside the coverage,
+// and inside a bounded world's edges
+func (c Coverage) samplePoint(g Geometry) (float64, float64) {
+	if c.Radius <= 0 {
+		return rand.Float64() * g.Width, rand.Float64() * g.Height
+	}
+	clipped := g.Bounded && c.Area(g) == 0 // Nowhere to put it
+	for {
+		r := c.Radius * math.Sqrt(rand.Float64())
+		theta := rand.Float64() * 2 * math.Pi
+		x, y := c.X+r*math.Cos(theta), c.Y+r*math.Sin(theta)
+		if g.inside(x, y) || clipped {
+			return g.wrap(x, g.Width), g.wrap(y, g.Height)
+		}
+	}
+}
+
+// Poisson returns a Poisson distributed count with the given mean
+func Poisson(mean float64) int {
+	if mean <= 0 {
+		return 0
+	}
+	if mean > 30 {
+		// Normal approximation is plenty for large means
+		n := int(math.Round(Gaussian(mean, math.Sqrt(mean))))
+		if n < 0 {
+			return 0
+		}
+		return n
+	}
+
+	// Knuth's multiplication method
+	limit := math.Exp(-mean)
+	n := 0
+	p := rand.Float64()
+	for p > limit {
+		n++
+		p *= rand.Float64()
+	}
+	return n
+}
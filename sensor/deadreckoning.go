@@ -0,0 +1,169 @@
+package sensor
+
+import (
+	"math"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	sensorpb "distributed-sensor-fusion/shared/generated/sensorpb"
+)
+
+// DeadReckoningConfig tunes report suppression. A target is only reported
+// when the receiver's constant velocity prediction from the last report
+// has drifted more than Threshold from the sensor's own estimate, or when
+// MaxSilence has passed since the last report.
+type DeadReckoningConfig struct {
+	Threshold  float64 `json:"threshold"`   // World units of tolerated prediction error
+	MaxSilence float64 `json:"max_silence"` // Seconds between forced reports
+	Alpha      float64 `json:"alpha"`       // Position smoothing gain of the estimator
+	Beta       float64 `json:"beta"`        // Velocity smoothing gain of the estimator
+}
+
+func DefaultDeadReckoningConfig() DeadReckoningConfig {
+	return DeadReckoningConfig{
+		Threshold:  3.0,
+		MaxSilence: 2.0,
+		Alpha:      0.5,
+		Beta:       0.2,
+	}
+}
+
+// DeadReckoningStats counts what suppression saved on the link
+type DeadReckoningStats struct {
+	Sent       int64
+	Suppressed int64
+	BytesSent  int64
+	BytesSaved int64
+}
+
+// drTarget is the sensor's alpha-beta estimate of one target next to the
+// model the receiver was last given. Velocities are in units per second.
+type drTarget struct {
+	x, y, vx, vy float64
+	seen         int64 // Nanosecond timestamp of the last detection
+
+	sentX, sentY, sentVX, sentVY float64
+	sentAt                       int64
+	reported                     bool
+}
+
+// deadReckoner decides which target readings are worth sending. Targets
+// are keyed by threat ID, standing in for the sensor's own association.
+type deadReckoner struct {
+	cfg     DeadReckoningConfig
+	targets map[int32]*drTarget
+
+	sent       atomic.Int64
+	suppressed atomic.Int64
+	bytesSent  atomic.Int64
+	bytesSaved atomic.Int64
+}
+
+func newDeadReckoner(cfg DeadReckoningConfig) *deadReckoner {
+	return &deadReckoner{
+		cfg:     cfg,
+		targets: make(map[int32]*drTarget),
+	}
+}
+
+// filter returns the detections to transmit. Sent target readings carry
+// the smoothed position and the velocity the receiver should extrapolate
+// with; clutter has no model and always goes out.
+func (dr *deadReckoner) filter(detections []detection, now int64, g Geometry) []detection {
+	maxSilence := int64(dr.cfg.MaxSilence * float64(time.Second))
+	out := detections[:0]
+
+	for _, d := range detections {
+		if d.truth.Origin != OriginTarget {
+			out = append(out, d)
+			dr.count(d.reading, true)
+			continue
+		}
+
+		t := dr.update(d.reading, g)
+		elapsed := float64(t.seen-t.sentAt) / float64(time.Second)
+		predX := t.sentX + t.sentVX*elapsed
+		predY := t.sentY + t.sentVY*elapsed
+		dx := g.shortestDelta(predX, t.x, g.Width)
+		dy := g.shortestDelta(predY, t.y, g.Height)
+
+		send := !t.reported ||
+			math.Sqrt(dx*dx+dy*dy) > dr.cfg.Threshold ||
+			t.seen-t.sentAt >= maxSilence
+		if !send {
+			dr.count(d.reading, false)
+			continue
+		}
+
+		t.sentX, t.sentY, t.sentVX, t.sentVY = t.x, t.y, t.vx, t.vy
+		t.sentAt = t.seen
+		t.reported = true
+
+		d.reading.X, d.reading.Y = t.x, t.y
+		d.reading.DeadReckoning = &sensorpb.DeadReckoning{
+			Vx:           t.vx,
+			Vy:           t.vy,
+			MaxSilenceMs: maxSilence / int64(time.Millisecond),
+		}
+		d.truth.X, d.truth.Y = t.x, t.y
+		out = append(out, d)
+		dr.count(d.reading, true)
+	}
+
+	// Forget targets that have been out of sight for a full silence interval
+	for id, t := range dr.targets {
+		if now-t.seen > maxSilence {
+			delete(dr.targets, id)
+		}
+	}
+	return out
+}
+
+// update folds a detection into its target's alpha-beta estimate
+func (dr *deadReckoner) update(r *sensorpb.SensorReading, g Geometry) *drTarget {
+	t, exists := dr.targets[r.ThreatId]
+	if !exists {
+		t = &drTarget{x: r.X, y: r.Y, seen: r.Timestamp}
+		dr.targets[r.ThreatId] = t
+		return t
+	}
+
+	dt := float64(r.Timestamp-t.seen) / float64(time.Second)
+	t.seen = r.Timestamp
+	if dt <= 0 {
+		return t
+	}
+
+	predX := t.x + t.vx*dt
+	predY := t.y + t.vy*dt
+	resX := g.shortestDelta(predX, r.X, g.Width)
+	resY := g.shortestDelta(predY, r.Y, g.Height)
+
+	t.x = g.wrap(predX+dr.cfg.Alpha*resX, g.Width)
+	t.y = g.wrap(predY+dr.cfg.Alpha*resY, g.Height)
+	t.vx += dr.cfg.Beta * resX / dt
+	t.vy += dr.cfg.Beta * resY / dt
+	return t
+}
+
+func (dr *deadReckoner) count(r *sensorpb.SensorReading, sent bool) {
+	size := int64(proto.Size(r))
+	if sent {
+		dr.sent.Add(1)
+		dr.bytesSent.Add(size)
+		return
+	}
+	dr.suppressed.Add(1)
+	dr.bytesSaved.Add(size)
+}
+
+func (dr *deadReckoner) stats() DeadReckoningStats {
+	return DeadReckoningStats{
+		Sent:       dr.sent.Load(),
+		Suppressed: dr.suppressed.Load(),
+		BytesSent:  dr.bytesSent.Load(),
+		BytesSaved: dr.bytesSaved.Load(),
+	}
+}
@@ -0,0 +1,94 @@
+package sensor
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// DetectionModel describes how likely a sensor is to see a threat.
+// Signal to noise ratio falls off with range and rises with threat level,
+// and probability of detection follows the Swerling I relation
+// Pd = Pfa^(1/(1+SNR)). A zero ReferenceRange disables the model and the
+// flat NoiseConfig.MissRate is used instead.
+type DetectionModel struct {
+	ReferenceSNR   float64 `json:"reference_snr"`    // SNR in dB of a level 1 threat at ReferenceRange
+	ReferenceRange float64 `json:"reference_range"`  // Range at which ReferenceSNR applies
+	RangeExponent  float64 `json:"range_exponent"`   // Path loss exponent, 4 for active radar, 2 for passive
+	LevelGain      float64 `json:"level_gain"`       // Extra SNR in dB per threat level above 1
+	FalseAlarmProb float64 `json:"false_alarm_prob"` // Detector threshold expressed as Pfa
+}
+
+// Enabled reports whether the model should replace the flat miss rate
+func (d DetectionModel) Enabled() bool {
+	return d.ReferenceRange > 0
+}
+
+// SNR returns the linear signal to noise ratio for a threat at distance r
+func (d DetectionModel) SNR(r float64, level int) float64 {
+	if r < 1 {
+		r = 1
+	}
+	db := d.ReferenceSNR + d.LevelGain*float64(level-1) -
+		10*d.RangeExponent*math.Log10(r/d.ReferenceRange)
+	return math.Pow(10, db/10)
+}
+
+// Pd returns the probability of detection for a given linear SNR
+func (d DetectionModel) Pd(snr float64) float64 {
+	return math.Pow(d.FalseAlarmProb, 1/(1+snr))
+}
+
+// Detect decides whether a threat at distance r is seen this scan. It also
+// returns a confidence derived from the SNR so strong returns weigh more in fusion.
+func (d DetectionModel) Detect(r float64, level int) (bool, float64) {
+	snr := d.SNR(r, level)
+	if rand.Float64() >= d.Pd(snr) {
+		return false, 0
+	}
+	return true, snr / (1 + snr)
+}
+
+// ValidSensorType checks a sensor type is one this package models: radar
+// (also the default, empty type), eo or optical, or acoustic
+func ValidSensorType(sensorType string) error {
+	switch sensorType {
+	case "", "radar", "eo", "optical", "acoustic":
+		return nil
+	}
+	return fmt.Errorf("unknown sensor type %q (want radar, eo or acoustic)", sensorType)
+}
+
+// DetectionModelFor returns the default detection curve for a sensor type
+func DetectionModelFor(sensorType string) (DetectionModel, error) {
+	if err := ValidSensorType(sensorType); err != nil {
+		return DetectionModel{}, err
+	}
+	switch sensorType {
+	case "eo", "optical":
+		// Short ranged but very sensitive to large threats
+		return DetectionModel{
+			ReferenceSNR:   18,
+			ReferenceRange: 20,
+			RangeExponent:  2,
+			LevelGain:      1.5,
+			FalseAlarmProb: 1e-4,
+		}, nil
+	case "acoustic":
+		return DetectionModel{
+			ReferenceSNR:   12,
+			ReferenceRange: 25,
+			RangeExponent:  2,
+			LevelGain:      1.0,
+			FalseAlarmProb: 1e-3,
+		}, nil
+	default:
+		return DetectionModel{
+			ReferenceSNR:   20,
+			ReferenceRange: 30,
+			RangeExponent:  4,
+			LevelGain:      1.0,
+			FalseAlarmProb: 1e-6,
+		}, nil
+	}
+}
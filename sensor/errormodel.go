@@ -0,0 +1,190 @@
+package sensor
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// NoiseModel selects how position errors evolve between scans
+type NoiseModel string
+
+const (
+	NoiseWhite        NoiseModel = "white"        // Independent Gaussian per reading
+	NoiseGaussMarkov  NoiseModel = "gauss-markov" // First-order temporally correlated error
+	NoiseRandomWalk   NoiseModel = "random-walk"  // Slowly drifting bias plus white noise
+	NoiseStudentT     NoiseModel = "student-t"    // Heavy-tailed Student-t error
+	NoiseContaminated NoiseModel = "contaminated" // Gaussian mixed with occasional wide outliers
+)
+
+// PositionError perturbs true positions according to a noise model.
+// Implementations may keep state between calls, so each sensor owns its own.
+type PositionError interface {
+	// NextScan advances time-correlated state by the world ticks elapsed
+	// since the previous scan
+	NextScan(elapsed float64)
+	Perturb(threatID int32, x, y float64) (float64, float64)
+}
+
+// gaussMarkovForget is how many correlation times a threat's error is
+// kept unused: by then it has decayed to under 1% and a fresh draw is as good
+const gaussMarkovForget = 5
+
+// NewPositionError builds the error model selected by nc.Model, white if
+// it is empty
+func NewPositionError(nc NoiseConfig) (PositionError, error) {
+	switch nc.Model {
+	case "", NoiseWhite:
+		return whiteError{nc: nc}, nil
+	case NoiseGaussMarkov:
+		tau := nc.CorrelationTime
+		if tau <= 0 {
+			tau = 1
+		}
+		return &gaussMarkovError{
+			stdDev: nc.PositionStdDev,
+			alpha:  math.Exp(-1 / tau),
+			forget: gaussMarkovForget * tau,
+			state:  make(map[int32]gaussMarkovState),
+		}, nil
+	case NoiseRandomWalk:
+		return &randomWalkError{stdDev: nc.PositionStdDev, stepStdDev: nc.BiasWalkStdDev}, nil
+	case NoiseStudentT:
+		dof := nc.DegreesOfFreedom
+		if dof < 1 {
+			dof = 3
+		}
+		return &studentTError{scale: nc.PositionStdDev, dof: dof}, nil
+	case NoiseContaminated:
+		return &contaminatedError{
+			stdDev:        nc.PositionStdDev,
+			outlierRate:   nc.OutlierRate,
+			outlierStdDev: nc.OutlierStdDev,
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown noise model %q (want %s, %s, %s, %s or %s)", nc.Model,
+		NoiseWhite, NoiseGaussMarkov, NoiseRandomWalk, NoiseStudentT, NoiseContaminated)
+}
+
+type whiteError struct {
+	nc NoiseConfig
+}
+
+func (e whiteError) NextScan(float64) {}
+
+func (e whiteError) Perturb(threatID int32, x, y float64) (float64, float64) {
+	return e.nc.AddPositionNoise(x, y)
+}
+
+// gaussMarkovError keeps an AR(1) error per threat so consecutive readings
+// of the same target are correlated while the marginal spread stays stdDev
+type gaussMarkovError struct {
+	stdDev float64
+	alpha  float64 // Decay per tick
+	forget float64 // Ticks after which an unseen threat's error is dropped
+	now    float64 // World time in ticks
+	state  map[int32]gaussMarkovState
+}
+
+type gaussMarkovState struct {
+	dx, dy float64
+	at     float64
+}
+
+func (e *gaussMarkovError) NextScan(elapsed float64) {
+	e.now += elapsed
+	for id, st := range e.state {
+		if e.now-st.at > e.forget {
+			delete(e.state, id)
+		}
+	}
+}
+
+func (e *gaussMarkovError) Perturb(threatID int32, x, y float64) (float64, float64) {
+	prev, ok := e.state[threatID]
+	if !ok {
+		// Start in steady state
+		prev = gaussMarkovState{dx: Gaussian(0, e.stdDev), dy: Gaussian(0, e.stdDev)}
+	} else if gap := e.now - prev.at; gap > 0 {
+		// Decay over all the time since the last reading, not just one scan
+		a := math.Pow(e.alpha, gap)
+		drive := e.stdDev * math.Sqrt(1-a*a)
+		prev.dx = a*prev.dx + Gaussian(0, drive)
+		prev.dy = a*prev.dy + Gaussian(0, drive)
+	}
+	prev.at = e.now
+	e.state[threatID] = prev
+	return x + prev.dx, y + prev.dy
+}
+
+// randomWalkError models a sensor-wide registration bias that drifts a
+// little every tick, on top of white measurement noise
+type randomWalkError struct {
+	stdDev     float64
+	stepStdDev float64
+	biasX      float64
+	biasY      float64
+}
+
+func (e *randomWalkError) NextScan(elapsed float64) {
+	step := e.stepStdDev * math.Sqrt(elapsed)
+	e.biasX += Gaussian(0, step)
+	e.biasY += Gaussian(0, step)
+}
+
+func (e *randomWalkError) Perturb(threatID int32, x, y float64) (float64, float64) {
+	return Gaussian(x+e.biasX, e.stdDev), Gaussian(y+e.biasY, e.stdDev)
+}
+
+type studentTError struct {
+	scale float64
+	dof   float64
+}
+
+func (e *studentTError) NextScan(float64) {}
+
+func (e *studentTError) Perturb(threatID int32, x, y float64) (float64, float64) {
+	return x + e.scale*studentT(e.dof), y + e.scale*studentT(e.dof)
+}
+
+type contaminatedError struct {
+	stdDev        float64
+	outlierRate   float64
+	outlierStdDev float64
+}
+
+func (e *contaminatedError) NextScan(float64) {}
+
+func (e *contaminatedError) Perturb(threatID int32, x, y float64) (float64, float64) {
+	if rand.Float64() < e.outlierRate {
+		return Gaussian(x, e.outlierStdDev), Gaussian(y, e.outlierStdDev)
+	}
+	return Gaussian(x, e.stdDev), Gaussian(y, e.stdDev)
+}
+
+// studentT draws from a standard Student-t distribution with dof degrees of freedom
+func studentT(dof float64) float64 {
+	return Gaussian(0, 1) / math.Sqrt(gammaSample(dof/2)*2/dof)
+}
+
+// gammaSample draws from Gamma(shape, 1) using Marsaglia and Tsang's method
+func gammaSample(shape float64) float64 {
+	if shape < 1 {
+		// Boost small shapes, then scale back down
+		return gammaSample(shape+1) * math.Pow(rand.Float64(), 1/shape)
+	}
+	d := shape - 1.0/3
+	c := 1 / math.Sqrt(9*d)
+	for {
+		z := Gaussian(0, 1)
+		v := 1 + c*z
+		if v <= 0 {
+			continue
+		}
+		v = v * v * v
+		u := rand.Float64()
+		if math.Log(u) < 0.5*z*z+d-d*v+d*math.Log(v) {
+			return d * v
+		}
+	}
+}
@@ -0,0 +1,489 @@
+package sensor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	worldpb "distributed-sensor-fusion/shared/generated/worldpb"
+)
+
+// FleetConfig describes many simulated sensors run from one process
+type FleetConfig struct {
+	WorldAddr        string       `json:"world_addr"`
+	CommandAddr      string       `json:"command_addr"`
+	SharedConnection bool         `json:"shared_connection"` // One CommandClient for the whole fleet
+	ReportInterval   float64      `json:"report_interval"`   // Seconds between rate reports
+	Sensors          []SensorSpec `json:"sensors"`
+}
+
+// SensorSpec is a single fleet member. Noise holds overrides applied on
+// top of DefaultNoiseConfig and the detection curve and velocity
+// measurement for Type.
+type SensorSpec struct {
+	ID    string          `json:"id"`
+	Type  string          `json:"type"`
+	X     float64         `json:"x"`
+	Y     float64         `json:"y"`
+	Range float64         `json:"range"` // Coverage radius, 0 for the whole world
+	Noise json.RawMessage `json:"noise"`
+
+	// Scan schedule: Rate scans per second, Phase seconds into each period,
+	// optionally interpolating truth. Rate 0 scans every world state.
+	Rate        float64 `json:"rate"`
+	Phase       float64 `json:"phase"`
+	Interpolate bool    `json:"interpolate"`
+
+	// Report local tracks instead of raw detections. Holds overrides of
+	// DefaultLocalTrackerConfig; {} enables tracking with the defaults.
+	LocalTracking json.RawMessage `json:"local_tracking,omitempty"`
+
+	// Suppress predictable target readings. Holds overrides of
+	// DefaultDeadReckoningConfig; {} enables it with the defaults.
+	DeadReckoning json.RawMessage `json:"dead_reckoning,omitempty"`
+
+	// Misbehave for robustness testing; see AdversaryConfig
+	Adversary AdversaryConfig `json:"adversary,omitempty"`
+
+	// Passive sensor reporting only bearings, with noise.bearing_std_dev
+	BearingOnly bool `json:"bearing_only"`
+
+	// Acoustic array element reporting only arrival times, with
+	// noise.timing_std_dev. Listens continuously, so Rate must be 0.
+	TDOA bool `json:"tdoa"`
+
+	// Clock skew and reporting latency; see TimingConfig
+	Timing TimingConfig `json:"timing,omitempty"`
+}
+
+// LoadFleetConfig reads and validates a JSON fleet config
+func LoadFleetConfig(path string) (*FleetConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &FleetConfig{
+		WorldAddr:      "localhost:50051",
+		CommandAddr:    "localhost:50052",
+		ReportInterval: 5,
+	}
+	if err := DecodeStrict(path, data, cfg); err != nil {
+		return nil, err
+	}
+
+	if len(cfg.Sensors) == 0 {
+		return nil, fmt.Errorf("%s: no sensors defined", path)
+	}
+	seen := make(map[string]bool)
+	for i, spec := range cfg.Sensors {
+		if spec.ID == "" {
+			return nil, fmt.Errorf("%s: sensors[%d]: missing id", path, i)
+		}
+		if seen[spec.ID] {
+			return nil, fmt.Errorf("%s: sensors[%d]: duplicate id %q", path, i, spec.ID)
+		}
+		seen[spec.ID] = true
+		if _, err := spec.NoiseConfig(); err != nil {
+			return nil, fmt.Errorf("%s: sensors[%d] (%s): %w", path, i, spec.ID, err)
+		}
+		if _, err := spec.TrackerConfig(); err != nil {
+			return nil, fmt.Errorf("%s: sensors[%d] (%s): %w", path, i, spec.ID, err)
+		}
+		if _, err := spec.DeadReckoningConfig(); err != nil {
+			return nil, fmt.Errorf("%s: sensors[%d] (%s): %w", path, i, spec.ID, err)
+		}
+		if err := spec.Timing.Validate(); err != nil {
+			return nil, fmt.Errorf("%s: sensors[%d] (%s): %w", path, i, spec.ID, err)
+		}
+		if spec.Rate < 0 || spec.Phase < 0 {
+			return nil, fmt.Errorf("%s: sensors[%d] (%s): rate and phase must not be negative", path, i, spec.ID)
+		}
+		if err := spec.validateMode(); err != nil {
+			return nil, fmt.Errorf("%s: sensors[%d] (%s): %w", path, i, spec.ID, err)
+		}
+	}
+
+	return cfg, nil
+}
+
+// DecodeStrict decodes a config's JSON into v, rejecting unknown fields so
+// a misspelt key isn't silently ignored. Errors name the file and, where the
+// decoder gives a position, the line. An empty file is for a fragment of a
+// config already read, such as a spec's noise overrides, whose own line
+// numbers would mislead.
+func DecodeStrict(file string, data []byte, v any) error {
+	at := func(offset int64) string {
+		if file == "" {
+			return ""
+		}
+		return fmt.Sprintf("%s:%d: ", file, lineOf(data, offset))
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	err := dec.Decode(v)
+	if err == nil {
+		return nil
+	}
+	var syntax *json.SyntaxError
+	var typ *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntax):
+		return fmt.Errorf("%s%w", at(syntax.Offset), err)
+	case errors.As(err, &typ):
+		return fmt.Errorf("%s%s: expected %s", at(typ.Offset), fieldPath(typ.Field), typ.Type)
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		// The decoder doesn't say where; the first use is a good guess
+		name := strings.TrimPrefix(err.Error(), "json: unknown field ")
+		if offset := bytes.Index(data, []byte(name)); offset >= 0 {
+			return fmt.Errorf("%sunknown field %s", at(int64(offset)), name)
+		}
+	}
+	if file == "" {
+		return err
+	}
+	return fmt.Errorf("parse %s: %w", file, err)
+}
+
+// lineOf turns a byte offset into a 1-based line number
+func lineOf(data []byte, offset int64) int {
+	return bytes.Count(data[:min(int(offset), len(data))], []byte("\n")) + 1
+}
+
+// fieldPath turns the decoder's "sensors.2.x" into "sensors[2].x"
+func fieldPath(field string) string {
+	parts := strings.Split(field, ".")
+	var b strings.Builder
+	for i, p := range parts {
+		if _, err := strconv.Atoi(p); err == nil {
+			fmt.Fprintf(&b, "[%s]", p)
+			continue
+		}
+		if i > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(p)
+	}
+	return b.String()
+}
+
+// NoiseConfig builds the sensor's noise settings from defaults and overrides
+func (spec SensorSpec) NoiseConfig() (NoiseConfig, error) {
+	nc := DefaultNoiseConfig()
+	if spec.Type != "" {
+		var err error
+		if nc.Detection, err = DetectionModelFor(spec.Type); err != nil {
+			return nc, fmt.Errorf("type: %w", err)
+		}
+		if nc.Velocity, err = VelocityModelFor(spec.Type); err != nil {
+			return nc, fmt.Errorf("type: %w", err)
+		}
+	}
+	if len(spec.Noise) > 0 {
+		if err := DecodeStrict("", spec.Noise, &nc); err != nil {
+			return nc, fmt.Errorf("noise: %w", err)
+		}
+	}
+	if _, err := NewPositionError(nc); err != nil {
+		return nc, fmt.Errorf("noise: %w", err)
+	}
+	return nc, nil
+}
+
+// TrackerConfig builds the local tracker settings from defaults and overrides
+func (spec SensorSpec) TrackerConfig() (LocalTrackerConfig, error) {
+	tc := DefaultLocalTrackerConfig()
+	if len(spec.LocalTracking) > 0 {
+		if err := DecodeStrict("", spec.LocalTracking, &tc); err != nil {
+			return tc, fmt.Errorf("local_tracking: %w", err)
+		}
+	}
+	return tc, nil
+}
+
+// DeadReckoningConfig builds the suppression settings from defaults and overrides
+func (spec SensorSpec) DeadReckoningConfig() (DeadReckoningConfig, error) {
+	dc := DefaultDeadReckoningConfig()
+	if len(spec.DeadReckoning) > 0 {
+		if err := DecodeStrict("", spec.DeadReckoning, &dc); err != nil {
+			return dc, fmt.Errorf("dead_reckoning: %w", err)
+		}
+	}
+	if dc.Threshold < 0 || dc.MaxSilence < 0 {
+		return dc, fmt.Errorf("dead_reckoning: threshold and max_silence must not be negative")
+	}
+	return dc, nil
+}
+
+// validateMode rejects combinations of reporting modes that can't work together
+func (spec SensorSpec) validateMode() error {
+	if spec.BearingOnly && spec.TDOA {
+		return fmt.Errorf("bearing_only and tdoa are exclusive")
+	}
+	if (spec.BearingOnly || spec.TDOA) && len(spec.LocalTracking) > 0 {
+		return fmt.Errorf("bearing_only and tdoa sensors can't run local tracking")
+	}
+	if len(spec.DeadReckoning) > 0 && (spec.BearingOnly || spec.TDOA || len(spec.LocalTracking) > 0) {
+		return fmt.Errorf("dead_reckoning only applies to position readings, not bearing_only, tdoa or local_tracking")
+	}
+	if spec.TDOA && spec.Rate > 0 {
+		return fmt.Errorf("tdoa sensors hear every emission, so can't have a scan rate")
+	}
+	return nil
+}
+
+// FleetStats is a snapshot of one member's traffic
+type FleetStats struct {
+	ID     string
+	Sent   int64
+	Errors int64
+	Client ClientStatus
+	Saved  DeadReckoningStats
+}
+
+type fleetMember struct {
+	sensor *Sensor
+	client *CommandClient
+	sent   atomic.Int64
+	errors atomic.Int64
+}
+
+// Fleet runs many sensors off a single world subscription
+type Fleet struct {
+	cfg     *FleetConfig
+	members []*fleetMember
+}
+
+// NewSensor builds the sensor the spec describes
+func (spec SensorSpec) NewSensor(worldAddr string) (*Sensor, error) {
+	nc, err := spec.NoiseConfig()
+	if err != nil {
+		return nil, err
+	}
+	s, err := NewSensor(spec.ID, worldAddr, nc)
+	if err != nil {
+		return nil, err
+	}
+	s.Type = spec.Type
+	s.Coverage = Coverage{X: spec.X, Y: spec.Y, Radius: spec.Range}
+	if spec.Rate > 0 {
+		s.Schedule = ScanSchedule{
+			Period:      time.Duration(float64(time.Second) / spec.Rate),
+			Phase:       time.Duration(spec.Phase * float64(time.Second)),
+			Interpolate: spec.Interpolate,
+		}
+	}
+	if err := spec.validateMode(); err != nil {
+		return nil, err
+	}
+	if spec.BearingOnly {
+		s.EnableBearingOnly()
+	}
+	if spec.TDOA {
+		s.EnableTDOA()
+	}
+	if len(spec.LocalTracking) > 0 {
+		tc, err := spec.TrackerConfig()
+		if err != nil {
+			return nil, err
+		}
+		s.EnableLocalTracking(tc)
+	}
+	if len(spec.DeadReckoning) > 0 {
+		dc, err := spec.DeadReckoningConfig()
+		if err != nil {
+			return nil, err
+		}
+		s.EnableDeadReckoning(dc)
+	}
+	if spec.Adversary.Enabled() {
+		s.EnableAdversary(spec.Adversary)
+	}
+	if spec.Timing.Enabled() {
+		if err := spec.Timing.Validate(); err != nil {
+			return nil, err
+		}
+		s.EnableTiming(spec.Timing)
+	}
+	return s, nil
+}
+
+func NewFleet(cfg *FleetConfig) (*Fleet, error) {
+	f := &Fleet{cfg: cfg}
+	for _, spec := range cfg.Sensors {
+		s, err := spec.NewSensor(cfg.WorldAddr)
+		if err != nil {
+			return nil, err
+		}
+		f.members = append(f.members, &fleetMember{sensor: s})
+	}
+	return f, nil
+}
+
+// Run connects every sensor and observes the world until ctx is cancelled
+func (f *Fleet) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	if err := f.connect(ctx); err != nil {
+		f.closeClients()
+		return err
+	}
+	defer f.closeClients()
+
+	var wg sync.WaitGroup
+	for _, m := range f.members {
+		wg.Add(3)
+		go func(m *fleetMember) {
+			defer wg.Done()
+			m.forward(ctx)
+		}(m)
+		go func(m *fleetMember) {
+			defer wg.Done()
+			m.client.KeepRegistered(ctx, m.sensor.Registration())
+		}(m)
+		go func(m *fleetMember) {
+			defer wg.Done()
+			m.sensor.RunScans(ctx)
+		}(m)
+	}
+
+	go f.report(ctx)
+
+	err := WatchWorld(ctx, f.cfg.WorldAddr, func(state *worldpb.WorldState) {
+		for _, m := range f.members {
+			m.sensor.Observe(state)
+		}
+	}, nil)
+
+	cancel()
+	wg.Wait()
+	for _, st := range f.Stats() {
+		log.Printf("[%s] final: sent=%d errors=%d", st.ID, st.Sent, st.Errors)
+	}
+
+	if err != nil && status.Code(err) != codes.Canceled {
+		return err
+	}
+	return nil
+}
+
+// Stats returns a snapshot of every member's counters. Members Run hasn't
+// connected yet show as connecting.
+func (f *Fleet) Stats() []FleetStats {
+	stats := make([]FleetStats, len(f.members))
+	for i, m := range f.members {
+		stats[i] = FleetStats{
+			ID:     m.sensor.ID,
+			Sent:   m.sent.Load(),
+			Errors: m.errors.Load(),
+			Client: ClientStatus{State: StateConnecting},
+			Saved:  m.sensor.DeadReckoningStats(),
+		}
+		if m.client != nil {
+			stats[i].Client = m.client.Status()
+		}
+	}
+	return stats
+}
+
+func (f *Fleet) connect(ctx context.Context) error {
+	if f.cfg.SharedConnection {
+		client := NewCommandClient(f.cfg.CommandAddr)
+		if err := client.Connect(ctx); err != nil {
+			return err
+		}
+		for _, m := range f.members {
+			m.client = client
+		}
+		return nil
+	}
+
+	for _, m := range f.members {
+		client := NewCommandClient(f.cfg.CommandAddr)
+		if err := client.Connect(ctx); err != nil {
+			return fmt.Errorf("[%s] %w", m.sensor.ID, err)
+		}
+		m.client = client
+	}
+	return nil
+}
+
+func (f *Fleet) closeClients() {
+	closed := make(map[*CommandClient]bool)
+	for _, m := range f.members {
+		if m.client != nil && !closed[m.client] {
+			m.client.Close()
+			closed[m.client] = true
+		}
+	}
+}
+
+func (m *fleetMember) forward(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case reading := <-m.sensor.Readings():
+			m.count(m.client.Send(reading))
+		case track := <-m.sensor.Tracks():
+			m.count(m.client.SendTrack(track))
+		case bearing := <-m.sensor.Bearings():
+			m.count(m.client.SendBearing(bearing))
+		case arrival := <-m.sensor.Arrivals():
+			m.count(m.client.SendArrival(arrival))
+		}
+	}
+}
+
+func (m *fleetMember) count(err error) {
+	if err != nil {
+		m.errors.Add(1)
+		return
+	}
+	m.sent.Add(1)
+}
+
+// report logs per-sensor send rates every ReportInterval
+func (f *Fleet) report(ctx context.Context) {
+	interval := time.Duration(f.cfg.ReportInterval * float64(time.Second))
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	lastSent := make(map[string]int64)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, st := range f.Stats() {
+				rate := float64(st.Sent-lastSent[st.ID]) / interval.Seconds()
+				lastSent[st.ID] = st.Sent
+				log.Printf("[%s] sent=%d errors=%d rate=%.1f/s link=%s queued=%d unacked=%d dropped=%d",
+					st.ID, st.Sent, st.Errors, rate, st.Client.State, st.Client.QueueDepth,
+					st.Client.Unacked, st.Client.Dropped)
+				if saved := st.Saved; saved.Suppressed > 0 {
+					log.Printf("[%s] dead reckoning suppressed=%d saved=%dB sent=%dB",
+						st.ID, saved.Suppressed, saved.BytesSaved, saved.BytesSent)
+				}
+			}
+		}
+	}
+}
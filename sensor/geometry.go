@@ -0,0 +1,99 @@
+package sensor
+
+import (
+	"math"
+
+	worldpb "distributed-sensor-fusion/shared/generated/worldpb"
+)
+
+// Geometry is the world's size and edges as the world server advertises
+// them. A torus wraps round, so distances take the short way across the
+// edges; a bounded world (reflecting or absorbing edges) is plain
+// Euclidean space.
+type Geometry struct {
+	Width   float64
+	Height  float64
+	Bounded bool
+}
+
+// GeometryOf reads the geometry of a world state. Older world servers
+// don't advertise their size or edges, which then default to a 100x100 torus.
+func GeometryOf(state *worldpb.WorldState) Geometry {
+	g := Geometry{Width: state.Width, Height: state.Height}
+	if g.Width <= 0 || g.Height <= 0 {
+		g.Width, g.Height = 100.0, 100.0
+	}
+	g.Bounded = state.Boundary != "" && state.Boundary != "torus"
+	return g
+}
+
+// shortestDelta returns to-from along one axis, in (-size/2, size/2] on
+// a torus
+func (g Geometry) shortestDelta(from, to, size float64) float64 {
+	if g.Bounded {
+		return to - from
+	}
+	d := math.Mod(to-from, size)
+	if d > size/2 {
+		d -= size
+	} else if d <= -size/2 {
+		d += size
+	}
+	return d
+}
+
+// wrap maps v into [0, size) on a torus, or clamps it to [0, size] in a
+// bounded world
+func (g Geometry) wrap(v, size float64) float64 {
+	if g.Bounded {
+		return math.Min(math.Max(v, 0), size)
+	}
+	v = math.Mod(v, size)
+	if v < 0 {
+		v += size
+	}
+	return v
+}
+
+// offset moves v by d along one axis, wrapping round a torus. In a
+// bounded world the result may lie beyond the edge, like a multipath image
+// mirrored in it.
+func (g Geometry) offset(v, d, size float64) float64 {
+	if g.Bounded {
+		return v + d
+	}
+	return g.wrap(v+d, size)
+}
+
+// distance returns the distance between two points, the short way round
+// on a torus
+func (g Geometry) distance(x1, y1, x2, y2 float64) float64 {
+	return math.Hypot(g.shortestDelta(x1, x2, g.Width), g.shortestDelta(y1, y2, g.Height))
+}
+
+// inside reports whether (x, y) is within the world. Every point is on a torus.
+func (g Geometry) inside(x, y float64) bool {
+	return !g.Bounded || x >= 0 && x <= g.Width && y >= 0 && y <= g.Height
+}
+
+// bounce moves v by d along one axis, wrapping round a torus or mirroring
+// off a bounded world's edges. It also reports whether the move ends up
+// reversed, having come off an odd number of edges.
+func (g Geometry) bounce(v, d, size float64) (float64, bool) {
+	v += d
+	if !g.Bounded {
+		return g.wrap(v, size), false
+	}
+	if v >= 0 && v <= size {
+		return v, false
+	}
+	bounces := math.Ceil(v/size) - 1
+	if v < 0 {
+		bounces = math.Ceil(-v / size)
+	}
+	m := math.Abs(math.Mod(v, 2*size))
+	if m > size {
+		m = 2*size - m
+	}
+	return m, math.Mod(bounces, 2) == 1
+}
@@ -0,0 +1,251 @@
+package sensor
+
+import (
+	"sort"
+
+	sensorpb "distributed-sensor-fusion/shared/generated/sensorpb"
+)
+
+// LocalTrackerConfig tunes a sensor's onboard tracker
+type LocalTrackerConfig struct {
+	Gate         float64 `json:"gate"`          // Max distance for associating a detection to a track
+	ProcessNoise float64 `json:"process_noise"` // Acceleration noise spectral density
+	ConfirmAfter int     `json:"confirm_after"` // Updates before a track is reported
+	MaxMisses    int     `json:"max_misses"`    // Consecutive missed scans before a track is dropped
+}
+
+func DefaultLocalTrackerConfig() LocalTrackerConfig {
+	return LocalTrackerConfig{
+		Gate:         10.0,
+		ProcessNoise: 0.05,
+		ConfirmAfter: 3,
+		MaxMisses:    3,
+	}
+}
+
+// localTrack is a constant velocity Kalman filter over [x, y, vx, vy].
+// Velocities are in world units per tick.
+type localTrack struct {
+	id      int32
+	x       [4]float64
+	p       [4][4]float64
+	updates int32
+	misses  int
+	level   int32
+	conf    float64
+}
+
+// LocalTracker runs nearest-neighbour tracking on one sensor's detections
+// so the sensor can report tracks instead of raw plots
+type LocalTracker struct {
+	cfg      LocalTrackerConfig
+	measVar  float64
+	tracks   map[int32]*localTrack
+	nextID   int32
+	lastScan float64 // World time of the last scan, in ticks
+	started  bool
+}
+
+func NewLocalTracker(cfg LocalTrackerConfig, measurementStdDev float64) *LocalTracker {
+	return &LocalTracker{
+		cfg:     cfg,
+		measVar: measurementStdDev * measurementStdDev,
+		tracks:  make(map[int32]*localTrack),
+		nextID:  1,
+	}
+}
+
+// Scan folds one scan's detections into the track picture and returns the
+// confirmed tracks that were updated this scan. at is the world time of the
+// scan in ticks, fractional for interpolated scans. A second scan at the
+// same time is ignored, since its detections would count twice; an earlier
+// time means the world went back, and the tracks start over.
+func (lt *LocalTracker) Scan(at float64, detections []*sensorpb.SensorReading, g Geometry) []*sensorpb.SensorTrack {
+	dt := 1.0
+	if lt.started {
+		switch {
+		case at == lt.lastScan:
+			return nil
+		case at < lt.lastScan:
+			lt.tracks = make(map[int32]*localTrack)
+		default:
+			dt = at - lt.lastScan
+		}
+	}
+	lt.lastScan = at
+	lt.started = true
+
+	for _, t := range lt.tracks {
+		t.predict(dt, lt.cfg.ProcessNoise, g)
+	}
+
+	// Greedy global nearest neighbour: closest pairs first
+	type pair struct {
+		track *localTrack
+		det   int
+		dist  float64
+	}
+	pairs := make([]pair, 0)
+	for _, t := range lt.tracks {
+		for i, d := range detections {
+			dist := g.distance(t.x[0], t.x[1], d.X, d.Y)
+			if dist <= lt.cfg.Gate {
+				pairs = append(pairs, pair{track: t, det: i, dist: dist})
+			}
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i].dist < pairs[j].dist })
+
+	usedTrack := make(map[int32]bool)
+	usedDet := make(map[int]bool)
+	updated := make([]*localTrack, 0)
+	for _, p := range pairs {
+		if usedTrack[p.track.id] || usedDet[p.det] {
+			continue
+		}
+		usedTrack[p.track.id] = true
+		usedDet[p.det] = true
+		p.track.update(detections[p.det], lt.measVar, g)
+		updated = append(updated, p.track)
+	}
+
+	for id, t := range lt.tracks {
+		if usedTrack[id] {
+			continue
+		}
+		t.misses++
+		if t.misses > lt.cfg.MaxMisses {
+			delete(lt.tracks, id)
+		}
+	}
+
+	for i, d := range detections {
+		if usedDet[i] {
+			continue
+		}
+		t := lt.initiate(d)
+		updated = append(updated, t)
+	}
+
+	reports := make([]*sensorpb.SensorTrack, 0, len(updated))
+	for _, t := range updated {
+		if int(t.updates) < lt.cfg.ConfirmAfter {
+			continue
+		}
+		reports = append(reports, t.report())
+	}
+	return reports
+}
+
+func (lt *LocalTracker) initiate(d *sensorpb.SensorReading) *localTrack {
+	t := &localTrack{
+		id:      lt.nextID,
+		x:       [4]float64{d.X, d.Y, 0, 0},
+		updates: 1,
+		level:   d.Level,
+		conf:    d.Confidence,
+	}
+	// Position as good as one measurement, velocity anything up to ~2/tick
+	t.p[0][0] = lt.measVar
+	t.p[1][1] = lt.measVar
+	t.p[2][2] = 4
+	t.p[3][3] = 4
+	lt.tracks[t.id] = t
+	lt.nextID++
+	return t
+}
+
+func (t *localTrack) predict(dt, q float64, g Geometry) {
+	// x = F x
+	t.x[0] = g.wrap(t.x[0]+t.x[2]*dt, g.Width)
+	t.x[1] = g.wrap(t.x[1]+t.x[3]*dt, g.Height)
+
+	// P = F P F' + Q, with F = [I dt*I; 0 I]
+	var fp [4][4]float64
+	for j := 0; j < 4; j++ {
+		fp[0][j] = t.p[0][j] + dt*t.p[2][j]
+		fp[1][j] = t.p[1][j] + dt*t.p[3][j]
+		fp[2][j] = t.p[2][j]
+		fp[3][j] = t.p[3][j]
+	}
+	for i := 0; i < 4; i++ {
+		t.p[i][0] = fp[i][0] + dt*fp[i][2]
+		t.p[i][1] = fp[i][1] + dt*fp[i][3]
+		t.p[i][2] = fp[i][2]
+		t.p[i][3] = fp[i][3]
+	}
+
+	// Continuous white noise acceleration model
+	dt2, dt3 := dt*dt, dt*dt*dt
+	for axis := 0; axis < 2; axis++ {
+		pos, vel := axis, axis+2
+		t.p[pos][pos] += q * dt3 / 3
+		t.p[pos][vel] += q * dt2 / 2
+		t.p[vel][pos] += q * dt2 / 2
+		t.p[vel][vel] += q * dt
+	}
+}
+
+func (t *localTrack) update(d *sensorpb.SensorReading, r float64, g Geometry) {
+	// Innovation, taking the short way round the torus
+	y := [2]float64{
+		g.shortestDelta(t.x[0], d.X, g.Width),
+		g.shortestDelta(t.x[1], d.Y, g.Height),
+	}
+
+	// S = H P H' + R, H picks out position
+	s00 := t.p[0][0] + r
+	s01 := t.p[0][1]
+	s10 := t.p[1][0]
+	s11 := t.p[1][1] + r
+	det := s00*s11 - s01*s10
+	if det == 0 {
+		return
+	}
+	inv := [2][2]float64{{s11 / det, -s01 / det}, {-s10 / det, s00 / det}}
+
+	// K = P H' S^-1
+	var k [4][2]float64
+	for i := 0; i < 4; i++ {
+		k[i][0] = t.p[i][0]*inv[0][0] + t.p[i][1]*inv[1][0]
+		k[i][1] = t.p[i][0]*inv[0][1] + t.p[i][1]*inv[1][1]
+	}
+
+	for i := 0; i < 4; i++ {
+		t.x[i] += k[i][0]*y[0] + k[i][1]*y[1]
+	}
+	t.x[0] = g.wrap(t.x[0], g.Width)
+	t.x[1] = g.wrap(t.x[1], g.Height)
+
+	// P = (I - K H) P
+	var np [4][4]float64
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			np[i][j] = t.p[i][j] - k[i][0]*t.p[0][j] - k[i][1]*t.p[1][j]
+		}
+	}
+	t.p = np
+
+	t.updates++
+	t.misses = 0
+	t.level = d.Level
+	t.conf = 0.7*t.conf + 0.3*d.Confidence
+}
+
+func (t *localTrack) report() *sensorpb.SensorTrack {
+	cov := make([]float64, 0, 16)
+	for i := 0; i < 4; i++ {
+		cov = append(cov, t.p[i][:]...)
+	}
+	return &sensorpb.SensorTrack{
+		LocalTrackId: t.id,
+		X:            t.x[0],
+		Y:            t.x[1],
+		Vx:           t.x[2],
+		Vy:           t.x[3],
+		Covariance:   cov,
+		UpdateCount:  t.updates,
+		Level:        t.level,
+		Confidence:   t.conf,
+	}
+}
@@ -0,0 +1,160 @@
+package sensor
+
+import (
+	"math"
+	"math/rand"
+
+	sensorpb "distributed-sensor-fusion/shared/generated/sensorpb"
+	worldpb "distributed-sensor-fusion/shared/generated/worldpb"
+)
+
+// OriginMultipath marks a ghost return: a real threat seen a second time at
+// its mirror image behind a reflecting surface
+const OriginMultipath ReadingOrigin = "multipath"
+
+// MultipathModel adds ghost returns off reflecting surfaces: obstacles,
+// and the world's edges if it has any. A threat near one may also be seen at its mirror
+// image, with the same (mirrored) position error and mirrored velocity, so
+// the ghost moves with it. Ghosts last several scans, as the geometry that
+// makes them does. A zero Rate disables it.
+type MultipathModel struct {
+	Rate        float64 `json:"rate"`         // Probability per scan that a threat near a reflector starts a ghost
+	Persistence float64 `json:"persistence"`  // Probability a ghost carries on into the next scan
+	MaxDistance float64 `json:"max_distance"` // How near a reflector a threat must be, in units
+	Attenuation float64 `json:"attenuation"`  // Ghost confidence as a fraction of the direct return's
+}
+
+// Enabled reports whether the model produces any ghosts
+func (mm MultipathModel) Enabled() bool {
+	return mm.Rate > 0
+}
+
+// reflector is a mirror line through (px, py) with unit normal (nx, ny)
+// facing the threat, in coordinates relative to the threat
+type reflector struct {
+	px, py, nx, ny float64
+}
+
+// mirror reflects the point (x, y) across the line
+func (r reflector) mirror(x, y float64) (float64, float64) {
+	d := (x-r.px)*r.nx + (y-r.py)*r.ny
+	return x - 2*d*r.nx, y - 2*d*r.ny
+}
+
+// mirrorVector reflects a direction, e.g. a velocity, across the line
+func (r reflector) mirrorVector(vx, vy float64) (float64, float64) {
+	d := vx*r.nx + vy*r.ny
+	return vx - 2*d*r.nx, vy - 2*d*r.ny
+}
+
+// ghost decides whether a threat detected this scan also shows a
+// multipath ghost, and builds it from the threat's direct detection
+func (s *Sensor) ghost(threat *worldpb.Threat, direct detection, obstacles []*worldpb.Obstacle, g Geometry) (detection, bool) {
+	mm := s.NoiseConfig.Multipath
+	r, ok := nearestReflector(s.Coverage.X, s.Coverage.Y, threat.X, threat.Y, mm.MaxDistance, obstacles, g)
+	if !ok {
+		delete(s.ghosts, threat.Id)
+		return detection{}, false
+	}
+	if s.ghosts[threat.Id] {
+		s.ghosts[threat.Id] = rand.Float64() < mm.Persistence
+	} else {
+		s.ghosts[threat.Id] = rand.Float64() < mm.Rate
+	}
+	if !s.ghosts[threat.Id] {
+		return detection{}, false
+	}
+
+	// Work relative to the threat so the mirror line doesn't straddle the seam
+	trueX, trueY := r.mirror(0, 0)
+	trueX, trueY = g.offset(threat.X, trueX, g.Width), g.offset(threat.Y, trueY, g.Height)
+	if !s.Coverage.Contains(trueX, trueY, g) {
+		return detection{}, false
+	}
+	gx, gy := r.mirror(g.shortestDelta(threat.X, direct.reading.X, g.Width), g.shortestDelta(threat.Y, direct.reading.Y, g.Height))
+	gx, gy = g.offset(threat.X, gx, g.Width), g.offset(threat.Y, gy, g.Height)
+	vx, vy := r.mirrorVector(threat.Vx, threat.Vy)
+
+	attenuation := mm.Attenuation
+	if attenuation <= 0 {
+		attenuation = 1
+	}
+	reading := &sensorpb.SensorReading{
+		SensorId:   s.ID,
+		ThreatId:   -1, // Not a threat of its own
+		X:          gx,
+		Y:          gy,
+		Level:      direct.reading.Level,
+		Timestamp:  direct.reading.Timestamp,
+		Confidence: direct.reading.Confidence * attenuation,
+		Velocity:   s.NoiseConfig.Velocity.measureVelocity(s.Coverage.X, s.Coverage.Y, trueX, trueY, vx, vy, g),
+	}
+	truth := direct.truth
+	truth.Origin = OriginMultipath
+	truth.X, truth.Y = gx, gy
+	truth.TrueX, truth.TrueY = trueX, trueY
+	return detection{reading: reading, truth: truth}, true
+}
+
+// nearestReflector finds the closest surface within maxDistance of the
+// threat at (tx, ty) that the sensor at (sx, sy) can bounce a return off,
+// i.e. one with the sensor on the threat's side. The result is relative
+// to the threat.
+func nearestReflector(sx, sy, tx, ty, maxDistance float64, obstacles []*worldpb.Obstacle, g Geometry) (reflector, bool) {
+	relSX, relSY := g.shortestDelta(tx, sx, g.Width), g.shortestDelta(ty, sy, g.Height)
+
+	var best reflector
+	bestDist := maxDistance
+	found := false
+	consider := func(r reflector, dist float64) {
+		facing := (relSX-r.px)*r.nx+(relSY-r.py)*r.ny > 0
+		if dist >= 0 && dist <= bestDist && facing {
+			best, bestDist, found = r, dist, true
+		}
+	}
+
+	// World edges, which are only walls in a bounded world; a torus has none
+	if g.Bounded {
+		consider(reflector{px: -tx, nx: 1}, tx)
+		consider(reflector{px: g.Width - tx, nx: -1}, g.Width-tx)
+		consider(reflector{py: -ty, ny: 1}, ty)
+		consider(reflector{py: g.Height - ty, ny: -1}, g.Height-ty)
+	}
+
+	for _, o := range obstacles {
+		if len(o.Vertices) == 0 {
+			cx, cy := g.shortestDelta(tx, o.X, g.Width), g.shortestDelta(ty, o.Y, g.Height)
+			d := math.Sqrt(cx*cx + cy*cy)
+			if d <= o.Radius {
+				continue // Inside
+			}
+			nx, ny := -cx/d, -cy/d
+			consider(reflector{px: cx + o.Radius*nx, py: cy + o.Radius*ny, nx: nx, ny: ny}, d-o.Radius)
+			continue
+		}
+
+		// Shift the whole polygon to the image nearest the threat
+		v0 := o.Vertices[0]
+		ox := g.shortestDelta(tx, v0.X, g.Width) - v0.X
+		oy := g.shortestDelta(ty, v0.Y, g.Height) - v0.Y
+		if insidePolygon(o.Vertices, ox, oy, 0, 0) {
+			continue
+		}
+		n := len(o.Vertices)
+		for k := 0; k < n; k++ {
+			ax, ay := o.Vertices[k].X+ox, o.Vertices[k].Y+oy
+			bx, by := o.Vertices[(k+1)%n].X+ox, o.Vertices[(k+1)%n].Y+oy
+			ex, ey := bx-ax, by-ay
+			l := math.Sqrt(ex*ex + ey*ey)
+			if l == 0 {
+				continue
+			}
+			nx, ny := -ey/l, ex/l
+			if -ax*nx-ay*ny < 0 {
+				nx, ny = -nx, -ny
+			}
+			consider(reflector{px: ax, py: ay, nx: nx, ny: ny}, segmentPointDistance(ax, ay, bx, by, 0, 0))
+		}
+	}
+	return best, found
+}
@@ -6,10 +6,24 @@ import (
 )
 
 type NoiseConfig struct {
-	PositionStdDev    float64 // Gaussian noise for X/Y
-	FalsePositiveRate float64 // Probability of hallucinating a threat
-	MissRate          float64 // Probability of missing a real threat
-	LevelVariance     int     // How much threat level can vary
+	PositionStdDev    float64 `json:"position_std_dev"`    // Gaussian noise for X/Y
+	FalsePositiveRate float64 `json:"false_positive_rate"` // Expected background false alarms per scan over the whole world
+	MissRate          float64 `json:"miss_rate"`           // Probability of missing a real threat, unless Detection is enabled
+	LevelVariance     int     `json:"level_variance"`      // How much threat level can vary
+
+	Model            NoiseModel `json:"model"`              // Position error model, white if empty
+	CorrelationTime  float64    `json:"correlation_time"`   // Gauss-Markov time constant, in world ticks
+	BiasWalkStdDev   float64    `json:"bias_walk_std_dev"`  // Random-walk bias step per world tick
+	DegreesOfFreedom float64    `json:"degrees_of_freedom"` // Student-t tail heaviness (lower is heavier)
+	OutlierRate      float64    `json:"outlier_rate"`       // Contaminated: probability a reading is an outlier
+	OutlierStdDev    float64    `json:"outlier_std_dev"`    // Contaminated: spread of outlier readings
+
+	ClutterHotSpots []ClutterHotSpot `json:"clutter_hot_spots"` // Areas of raised false alarm density
+	Detection       DetectionModel   `json:"detection"`         // Range and level dependent Pd, see DetectionModelFor
+	Velocity        VelocityModel    `json:"velocity"`          // Doppler or full velocity measurement, see VelocityModelFor
+	BearingStdDev   float64          `json:"bearing_std_dev"`   // Bearing noise in radians, for bearing-only sensors
+	TimingStdDev    float64          `json:"timing_std_dev"`    // Arrival time noise in seconds, for TDOA sensors
+	Multipath       MultipathModel   `json:"multipath"`         // Ghost returns off edges and obstacles, off unless rate is set
 }
 
 func DefaultNoiseConfig() NoiseConfig {
@@ -18,6 +32,19 @@ func DefaultNoiseConfig() NoiseConfig {
 		FalsePositiveRate: 0.05,
 		MissRate:          0.1,
 		LevelVariance:     2,
+		Model:             NoiseWhite,
+		CorrelationTime:   10,
+		BiasWalkStdDev:    0.1,
+		DegreesOfFreedom:  3,
+		OutlierRate:       0.05,
+		OutlierStdDev:     15.0,
+		BearingStdDev:     0.02,
+		TimingStdDev:      0.0005,
+		Multipath: MultipathModel{
+			Persistence: 0.8,
+			MaxDistance: 15,
+			Attenuation: 0.7,
+		},
 	}
 }
 
@@ -34,10 +61,6 @@ func (nc NoiseConfig) ShouldMiss() bool {
 	return rand.Float64() < nc.MissRate
 }
 
-func (nc NoiseConfig) ShouldFalsePositive() bool {
-	return rand.Float64() < nc.FalsePositiveRate
-}
-
 func (nc NoiseConfig) AddPositionNoise(x, y float64) (float64, float64) {
 	return Gaussian(x, nc.PositionStdDev), Gaussian(y, nc.PositionStdDev)
 }
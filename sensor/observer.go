@@ -2,9 +2,12 @@ package sensor
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"io"
 	"log"
 	"math/rand"
+	"sync"
 	"time"
 
 	"google.golang.org/grpc"
@@ -14,28 +17,165 @@ import (
 	worldpb "distributed-sensor-fusion/shared/generated/worldpb"
 )
 
+// ErrWorldUnreachable ends Start when the world server has been out of
+// reach for longer than the sensor's WorldTimeout
+var ErrWorldUnreachable = errors.New("world server unreachable")
+
 type Sensor struct {
-	ID          string
-	NoiseConfig NoiseConfig
-	WorldAddr   string
-	readings    chan *sensorpb.SensorReading
+	ID           string
+	Type         string
+	NoiseConfig  NoiseConfig
+	WorldAddr    string
+	WorldTimeout time.Duration // Start gives up after this long without a world state, 0 never does
+	Coverage     Coverage
+	Schedule     ScanSchedule
+	latest       latestWorld
+	readings     chan *sensorpb.SensorReading
+	truth        chan TruthRecord
+	posError     PositionError
+	ghosts       map[int32]bool // Threats showing a multipath ghost last scan
+	tracker      *LocalTracker
+	tracks       chan *sensorpb.SensorTrack
+	bearings     chan *sensorpb.BearingReading
+	arrivals     chan *sensorpb.ArrivalReading
+	reckoner     *deadReckoner
+	adversary    *adversary
+	clock        *sensorClock
+	epoch        int32   // World epoch the state below belongs to
+	lastScan     float64 // World time of the previous scan, in ticks
+	scanned      bool    // Whether lastScan is set
+
+	worldStateMu sync.RWMutex
+	worldState   ConnState
 }
 
-func NewSensor(id string, worldAddr string, noise NoiseConfig) *Sensor {
+// NewSensor builds a sensor, failing if the noise config names an
+// unknown model
+func NewSensor(id string, worldAddr string, noise NoiseConfig) (*Sensor, error) {
+	posError, err := NewPositionError(noise)
+	if err != nil {
+		return nil, err
+	}
 	return &Sensor{
 		ID:          id,
 		NoiseConfig: noise,
 		WorldAddr:   worldAddr,
 		readings:    make(chan *sensorpb.SensorReading, 100),
-	}
+		truth:       make(chan TruthRecord, 100),
+		posError:    posError,
+		ghosts:      make(map[int32]bool),
+		worldState:  StateConnecting,
+	}, nil
 }
 
 func (s *Sensor) Readings() <-chan *sensorpb.SensorReading {
 	return s.readings
 }
 
+// EnableLocalTracking switches the sensor to reporting local tracks on
+// Tracks() instead of raw detections on Readings(). Call before Start.
+func (s *Sensor) EnableLocalTracking(cfg LocalTrackerConfig) {
+	s.tracker = NewLocalTracker(cfg, s.NoiseConfig.PositionStdDev)
+	s.tracks = make(chan *sensorpb.SensorTrack, 100)
+}
+
+// Tracks returns local track reports. It is nil unless local tracking is enabled.
+func (s *Sensor) Tracks() <-chan *sensorpb.SensorTrack {
+	return s.tracks
+}
+
+// EnableDeadReckoning holds back target readings the command server can
+// predict from earlier ones. It only applies to position readings, so has
+// no effect on bearing-only, TDOA or local tracking sensors. Call before Start.
+func (s *Sensor) EnableDeadReckoning(cfg DeadReckoningConfig) {
+	s.reckoner = newDeadReckoner(cfg)
+}
+
+// DeadReckoningStats reports what suppression has saved so far. It is zero
+// unless dead reckoning is enabled.
+func (s *Sensor) DeadReckoningStats() DeadReckoningStats {
+	if s.reckoner == nil {
+		return DeadReckoningStats{}
+	}
+	return s.reckoner.stats()
+}
+
+// EnableAdversary makes the sensor misbehave as configured, for testing
+// how the command server copes with a compromised sensor. Call before Start.
+func (s *Sensor) EnableAdversary(cfg AdversaryConfig) {
+	s.adversary = newAdversary(s.ID, cfg)
+	log.Printf("[%s] Adversarial mode: %+v", s.ID, cfg)
+}
+
+// EnableTiming gives the sensor a skewed clock and a lossy-latency link.
+// Call before Start.
+func (s *Sensor) EnableTiming(cfg TimingConfig) {
+	s.clock = newSensorClock(cfg)
+}
+
+// now is the sensor clock, true time unless timing is enabled
+func (s *Sensor) now() int64 {
+	return s.clockAt(time.Now())
+}
+
+// clockAt is what the sensor clock reads at true time t
+func (s *Sensor) clockAt(t time.Time) int64 {
+	if s.clock == nil {
+		return t.UnixNano()
+	}
+	return s.clock.at(t)
+}
+
+// deliver hands a report to its channel after the link's latency. Each
+// report is delayed independently, so they can overtake one another.
+func (s *Sensor) deliver(send func()) {
+	if s.clock != nil {
+		if delay := s.clock.latency(); delay > 0 {
+			time.AfterFunc(delay, send)
+			return
+		}
+	}
+	send()
+}
+
+// Start observes the world until ctx is cancelled, resubscribing with
+// backoff whenever the world server goes away
 func (s *Sensor) Start(ctx context.Context) error {
-	conn, err := grpc.Dial(s.WorldAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	log.Printf("[%s] Connecting to world server", s.ID)
+	go s.RunScans(ctx)
+	return watchWorld(ctx, s.WorldAddr, s.Observe, s.setWorldConnState, s.WorldTimeout)
+}
+
+// WorldConnState reports the health of the sensor's world subscription
+func (s *Sensor) WorldConnState() ConnState {
+	s.worldStateMu.RLock()
+	defer s.worldStateMu.RUnlock()
+	return s.worldState
+}
+
+func (s *Sensor) setWorldConnState(state ConnState) {
+	s.worldStateMu.Lock()
+	s.worldState = state
+	s.worldStateMu.Unlock()
+}
+
+// Observe runs one scan against a world state. Callers that share a single
+// world subscription between many sensors feed each state through here.
+//
+// Sensors with a scan schedule only record the state here; RunScans
+// samples it on the sensor's own schedule.
+func (s *Sensor) Observe(state *worldpb.WorldState) {
+	if s.Schedule.Period > 0 {
+		s.latest.store(state)
+		return
+	}
+	s.processWorldState(state, float64(state.Tick))
+}
+
+// SubscribeWorld streams world states from addr into handle until the
+// stream ends or ctx is cancelled
+func SubscribeWorld(ctx context.Context, addr string, handle func(*worldpb.WorldState)) error {
+	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	if err != nil {
 		return err
 	}
@@ -47,7 +187,7 @@ func (s *Sensor) Start(ctx context.Context) error {
 		return err
 	}
 
-	log.Printf("[%s] Connected to world server", s.ID)
+	log.Printf("Subscribed to world server at %s", addr)
 
 	for {
 		state, err := stream.Recv()
@@ -58,56 +198,248 @@ func (s *Sensor) Start(ctx context.Context) error {
 			return err
 		}
 
-		s.processWorldState(state)
+		handle(state)
 	}
 
 	return nil
 }
 
-func (s *Sensor) processWorldState(state *worldpb.WorldState) {
-	now := time.Now().UnixNano()
+// WatchWorld keeps a world subscription alive, resubscribing with backoff
+// whenever the stream ends, until ctx is cancelled. onState may be nil.
+func WatchWorld(ctx context.Context, addr string, handle func(*worldpb.WorldState), onState func(ConnState)) error {
+	return watchWorld(ctx, addr, handle, onState, 0)
+}
+
+// watchWorld is WatchWorld, failing with ErrWorldUnreachable once no state
+// has arrived for giveUp, if that is positive
+func watchWorld(ctx context.Context, addr string, handle func(*worldpb.WorldState), onState func(ConnState), giveUp time.Duration) error {
+	if onState == nil {
+		onState = func(ConnState) {}
+	}
+	defer onState(StateClosed)
+
+	backoff := DefaultBackoff()
+	lastLive := time.Now()
+	for {
+		onState(StateConnecting)
+		connected := false
+		err := SubscribeWorld(ctx, addr, func(state *worldpb.WorldState) {
+			// Only a delivered state proves the subscription is live
+			if !connected {
+				connected = true
+				onState(StateConnected)
+				backoff.Reset()
+			}
+			handle(state)
+		})
+		if ctx.Err() != nil {
+			return nil
+		}
+		if connected {
+			lastLive = time.Now()
+		}
+		if giveUp > 0 && time.Since(lastLive) >= giveUp {
+			return fmt.Errorf("%w: no state from %s for %v (%v)", ErrWorldUnreachable, addr, giveUp, err)
+		}
+
+		onState(StateDisconnected)
+		delay := backoff.Next()
+		log.Printf("World stream from %s lost (%v), retrying in %v", addr, err, delay)
+		if !sleepCtx(ctx, delay) {
+			return nil
+		}
+	}
+}
+
+// forgetWorld drops everything kept about the world's threats, after the
+// world server has replaced the world with one that doesn't follow on
+func (s *Sensor) forgetWorld() {
+	log.Printf("[%s] World replaced (epoch %d), resetting", s.ID, s.epoch)
+	s.posError, _ = NewPositionError(s.NoiseConfig) // Checked by NewSensor
+	s.ghosts = make(map[int32]bool)
+	s.scanned = false
+	if s.tracker != nil {
+		s.tracker = NewLocalTracker(s.tracker.cfg, s.NoiseConfig.PositionStdDev)
+	}
+	if s.reckoner != nil {
+		s.reckoner.targets = make(map[int32]*drTarget)
+	}
+}
+
+// detection is one reading produced by a scan, with the ground truth behind it
+type detection struct {
+	reading *sensorpb.SensorReading
+	truth   TruthRecord
+
+	spoofX, spoofY float64 // Adversarial offset in the reported position, for bearings to follow
+}
+
+// processWorldState runs one scan of state, which shows the world as of
+// at, in ticks
+func (s *Sensor) processWorldState(state *worldpb.WorldState, at float64) {
+	if state.Epoch != s.epoch {
+		s.epoch = state.Epoch
+		s.forgetWorld()
+	}
+
+	now := s.now()
+	elapsed := 1.0
+	if s.scanned {
+		elapsed = max(at-s.lastScan, 0)
+	}
+	s.lastScan, s.scanned = at, true
+	s.posError.NextScan(elapsed)
+
+	g := GeometryOf(state)
+
+	detections := make([]detection, 0, len(state.Threats))
 
 	// Process real threats (with possible misses)
 	for _, threat := range state.Threats {
-		if s.NoiseConfig.ShouldMiss() {
+		if !s.Coverage.Contains(threat.X, threat.Y, g) {
+			continue
+		}
+		// Hidden behind an obstacle: a miss like any other
+		if !lineOfSight(s.Coverage.X, s.Coverage.Y, threat.X, threat.Y, state.Obstacles, g) {
+			continue
+		}
+		detected, confidence := s.detect(threat, g)
+		if !detected {
 			continue
 		}
 
-		noisyX, noisyY := s.NoiseConfig.AddPositionNoise(threat.X, threat.Y)
+		noisyX, noisyY := s.posError.Perturb(threat.Id, threat.X, threat.Y)
 		noisyLevel := s.NoiseConfig.AddLevelNoise(int(threat.Level))
+		velocity := s.NoiseConfig.Velocity.measureVelocity(s.Coverage.X, s.Coverage.Y,
+			threat.X, threat.Y, threat.Vx, threat.Vy, g)
 
-		reading := &sensorpb.SensorReading{
-			SensorId:   s.ID,
-			ThreatId:   threat.Id,
-			X:          noisyX,
-			Y:          noisyY,
-			Level:      int32(noisyLevel),
-			Timestamp:  now,
-			Confidence: 0.7 + rand.Float64()*0.3, // 0.7 to 1.0
+		direct := detection{
+			reading: &sensorpb.SensorReading{
+				SensorId:   s.ID,
+				ThreatId:   threat.Id,
+				X:          noisyX,
+				Y:          noisyY,
+				Level:      int32(noisyLevel),
+				Timestamp:  now,
+				Confidence: confidence,
+				Velocity:   velocity,
+			},
+			truth: TruthRecord{
+				SensorID:  s.ID,
+				Timestamp: now,
+				Origin:    OriginTarget,
+				ThreatID:  threat.Id,
+				X:         noisyX,
+				Y:         noisyY,
+				TrueX:     threat.X,
+				TrueY:     threat.Y,
+			},
 		}
+		detections = append(detections, direct)
 
-		select {
-		case s.readings <- reading:
-		default:
-			log.Printf("[%s] Reading channel full, dropping", s.ID)
+		if s.NoiseConfig.Multipath.Enabled() {
+			if ghost, ok := s.ghost(threat, direct, state.Obstacles, g); ok {
+				detections = append(detections, ghost)
+			}
 		}
 	}
 
-	// Generate false positives
-	if s.NoiseConfig.ShouldFalsePositive() {
-		reading := &sensorpb.SensorReading{
-			SensorId:   s.ID,
-			ThreatId:   -1, // Fake threat
-			X:          rand.Float64() * 100,
-			Y:          rand.Float64() * 100,
-			Level:      int32(rand.Intn(5) + 1),
-			Timestamp:  now,
-			Confidence: 0.3 + rand.Float64()*0.4, // 0.3 to 0.7
+	// Generate false alarms from the clutter model
+	for _, p := range s.NoiseConfig.GenerateClutter(s.Coverage, g) {
+		detections = append(detections, detection{
+			reading: &sensorpb.SensorReading{
+				SensorId:   s.ID,
+				ThreatId:   -1, // Fake threat
+				X:          p.X,
+				Y:          p.Y,
+				Level:      int32(rand.Intn(5) + 1),
+				Timestamp:  now,
+				Confidence: 0.3 + rand.Float64()*0.4, // 0.3 to 0.7
+				Velocity:   s.NoiseConfig.Velocity.clutterVelocity(s.Coverage.X, s.Coverage.Y, p.X, p.Y, g),
+			},
+			truth: TruthRecord{
+				SensorID:  s.ID,
+				Timestamp: now,
+				Origin:    OriginClutter,
+				ThreatID:  -1,
+				X:         p.X,
+				Y:         p.Y,
+				TrueX:     p.X,
+				TrueY:     p.Y,
+			},
+		})
+	}
+
+	if s.adversary != nil {
+		detections = s.adversary.apply(detections, now, s.Coverage, g)
+	}
+
+	if s.bearings != nil {
+		s.publishBearings(now, detections, g)
+		return
+	}
+
+	if s.arrivals != nil {
+		s.publishArrivals(state.Tick, state.Timestamp, detections, g)
+		return
+	}
+
+	if s.tracker != nil {
+		s.publishTracks(at, now, detections, g)
+		return
+	}
+
+	if s.reckoner != nil {
+		detections = s.reckoner.filter(detections, now, g)
+	}
+
+	for _, d := range detections {
+		s.deliver(func() {
+			select {
+			case s.readings <- d.reading:
+				s.emitTruth(d.truth)
+			default:
+				log.Printf("[%s] Reading channel full, dropping", s.ID)
+			}
+		})
+	}
+}
+
+// publishTracks feeds a scan through the local tracker and sends the
+// resulting track reports instead of the raw detections
+func (s *Sensor) publishTracks(at float64, now int64, detections []detection, g Geometry) {
+	readings := make([]*sensorpb.SensorReading, len(detections))
+	for i, d := range detections {
+		readings[i] = d.reading
+		s.emitTruth(d.truth)
+	}
+
+	for _, track := range s.tracker.Scan(at, readings, g) {
+		track.SensorId = s.ID
+		track.Timestamp = now
+		if s.adversary != nil {
+			s.adversary.tamperTrack(track)
 		}
 
-		select {
-		case s.readings <- reading:
-		default:
+		s.deliver(func() {
+			select {
+			case s.tracks <- track:
+			default:
+				log.Printf("[%s] Track channel full, dropping", s.ID)
+			}
+		})
+	}
+}
+
+// detect decides whether a threat is seen this scan and how confident the sensor is
+func (s *Sensor) detect(threat *worldpb.Threat, g Geometry) (bool, float64) {
+	if !s.NoiseConfig.Detection.Enabled() {
+		if s.NoiseConfig.ShouldMiss() {
+			return false, 0
 		}
+		return true, 0.7 + rand.Float64()*0.3 // 0.7 to 1.0
 	}
+
+	r := g.distance(s.Coverage.X, s.Coverage.Y, threat.X, threat.Y)
+	return s.NoiseConfig.Detection.Detect(r, int(threat.Level))
 }
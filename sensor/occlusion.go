@@ -0,0 +1,91 @@
+package sensor
+
+import (
+	"math"
+
+	worldpb "distributed-sensor-fusion/shared/generated/worldpb"
+)
+
+// lineOfSight reports whether the straight path from (sx, sy) to (tx, ty)
+// is clear of obstacles. On a torus the path is the shortest one across
+// it, so obstacles are also tested at their images one world over in each
+// direction; a bounded world has only the obstacles themselves.
+func lineOfSight(sx, sy, tx, ty float64, obstacles []*worldpb.Obstacle, g Geometry) bool {
+	if len(obstacles) == 0 {
+		return true
+	}
+
+	ex := sx + g.shortestDelta(sx, tx, g.Width)
+	ey := sy + g.shortestDelta(sy, ty, g.Height)
+
+	images := 1
+	if g.Bounded {
+		images = 0
+	}
+	for _, o := range obstacles {
+		for i := -images; i <= images; i++ {
+			for j := -images; j <= images; j++ {
+				ox, oy := float64(i)*g.Width, float64(j)*g.Height
+				if blocks(o, ox, oy, sx, sy, ex, ey) {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// blocks tests one image of an obstacle, shifted by (ox, oy), against the
+// segment (ax, ay)-(bx, by). A segment that starts or ends inside counts.
+func blocks(o *worldpb.Obstacle, ox, oy, ax, ay, bx, by float64) bool {
+	if len(o.Vertices) == 0 {
+		return segmentPointDistance(ax, ay, bx, by, o.X+ox, o.Y+oy) <= o.Radius
+	}
+
+	n := len(o.Vertices)
+	for k := 0; k < n; k++ {
+		p, q := o.Vertices[k], o.Vertices[(k+1)%n]
+		if segmentsIntersect(ax, ay, bx, by, p.X+ox, p.Y+oy, q.X+ox, q.Y+oy) {
+			return true
+		}
+	}
+	return insidePolygon(o.Vertices, ox, oy, ax, ay) || insidePolygon(o.Vertices, ox, oy, bx, by)
+}
+
+func segmentPointDistance(ax, ay, bx, by, px, py float64) float64 {
+	dx, dy := bx-ax, by-ay
+	lenSq := dx*dx + dy*dy
+	t := 0.0
+	if lenSq > 0 {
+		t = math.Max(0, math.Min(1, ((px-ax)*dx+(py-ay)*dy)/lenSq))
+	}
+	cx, cy := ax+t*dx-px, ay+t*dy-py
+	return math.Sqrt(cx*cx + cy*cy)
+}
+
+func segmentsIntersect(ax, ay, bx, by, cx, cy, dx, dy float64) bool {
+	d1 := cross(cx, cy, dx, dy, ax, ay)
+	d2 := cross(cx, cy, dx, dy, bx, by)
+	d3 := cross(ax, ay, bx, by, cx, cy)
+	d4 := cross(ax, ay, bx, by, dx, dy)
+	return ((d1 > 0) != (d2 > 0)) && ((d3 > 0) != (d4 > 0))
+}
+
+// cross is the z component of (b-a) x (p-a)
+func cross(ax, ay, bx, by, px, py float64) float64 {
+	return (bx-ax)*(py-ay) - (by-ay)*(px-ax)
+}
+
+// insidePolygon is an even-odd ray cast against the shifted polygon
+func insidePolygon(vertices []*worldpb.Point, ox, oy, px, py float64) bool {
+	inside := false
+	n := len(vertices)
+	for i, j := 0, n-1; i < n; j, i = i, i+1 {
+		xi, yi := vertices[i].X+ox, vertices[i].Y+oy
+		xj, yj := vertices[j].X+ox, vertices[j].Y+oy
+		if (yi > py) != (yj > py) && px < (xj-xi)*(py-yi)/(yj-yi)+xi {
+			inside = !inside
+		}
+	}
+	return inside
+}
@@ -0,0 +1,339 @@
+package sensor
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	sensorpb "distributed-sensor-fusion/shared/generated/sensorpb"
+
+	"google.golang.org/protobuf/encoding/protodelim"
+	"google.golang.org/protobuf/proto"
+)
+
+// clientStream is the sending half of a client-streaming RPC
+type clientStream[T proto.Message] interface {
+	Send(T) error
+	CloseAndRecv() (*sensorpb.Ack, error)
+}
+
+type queued[T proto.Message] struct {
+	msg      T
+	enqueued time.Time
+}
+
+// outbox is a bounded store-and-forward queue feeding one client stream.
+// A background loop owns the stream, reconnecting with backoff and
+// replaying whatever was queued while the server was unreachable.
+type outbox[T proto.Message] struct {
+	name      string
+	opts      ClientOptions
+	queueFile string
+	open      func(context.Context) (clientStream[T], error)
+	newMsg    func() T
+	timestamp func(T) int64
+
+	mu         sync.Mutex
+	cond       *sync.Cond
+	queue      []queued[T]
+	state      ConnState
+	used       bool // Whether anything was ever queued
+	dropped    int64
+	reconnects int64
+	closed     bool
+	done       chan struct{}
+}
+
+func newOutbox[T proto.Message](name string, opts ClientOptions, queueFile string,
+	open func(context.Context) (clientStream[T], error), newMsg func() T, timestamp func(T) int64) *outbox[T] {
+	o := &outbox[T]{
+		name:      name,
+		opts:      opts,
+		queueFile: queueFile,
+		open:      open,
+		newMsg:    newMsg,
+		timestamp: timestamp,
+		state:     StateConnecting,
+		done:      make(chan struct{}),
+	}
+	o.cond = sync.NewCond(&o.mu)
+	return o
+}
+
+func (o *outbox[T]) start(ctx context.Context) {
+	if o.queueFile != "" {
+		if err := o.load(); err != nil {
+			log.Printf("Could not restore %s queue from %s: %v", o.name, o.queueFile, err)
+		}
+	}
+
+	go func() {
+		// Wake the sender so it notices cancellation
+		<-ctx.Done()
+		o.mu.Lock()
+		o.cond.Broadcast()
+		o.mu.Unlock()
+	}()
+	go o.run(ctx)
+}
+
+func (o *outbox[T]) push(msg T) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.closed {
+		return ErrClientClosed
+	}
+
+	if o.opts.MaxQueue > 0 && len(o.queue) >= o.opts.MaxQueue {
+		o.queue = o.queue[1:]
+		o.dropped++
+	}
+	o.queue = append(o.queue, queued[T]{msg: msg, enqueued: time.Now()})
+	o.used = true
+	o.cond.Signal()
+	return nil
+}
+
+// close refuses further messages. The sender delivers what is queued and
+// then exits.
+func (o *outbox[T]) close() {
+	o.mu.Lock()
+	o.closed = true
+	o.cond.Broadcast()
+	o.mu.Unlock()
+}
+
+// stop waits for the sender to exit and persists anything left over
+func (o *outbox[T]) stop() {
+	o.close()
+	<-o.done
+
+	if o.queueFile != "" {
+		if err := o.save(); err != nil {
+			log.Printf("Could not persist %s queue to %s: %v", o.name, o.queueFile, err)
+		}
+	}
+}
+
+// status snapshots one outbox. State is empty if it has never been used.
+func (o *outbox[T]) status() ClientStatus {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	st := ClientStatus{
+		QueueDepth: len(o.queue),
+		Dropped:    o.dropped,
+		Reconnects: o.reconnects,
+	}
+	if o.used {
+		st.State = o.state
+	}
+	return st
+}
+
+func (o *outbox[T]) setState(state ConnState) {
+	o.mu.Lock()
+	o.state = state
+	o.mu.Unlock()
+}
+
+// waitForWork blocks until something is queued, reporting false if ctx
+// ends or the outbox is closed with nothing left to send
+func (o *outbox[T]) waitForWork(ctx context.Context) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for len(o.queue) == 0 && !o.closed && ctx.Err() == nil {
+		o.cond.Wait()
+	}
+	return len(o.queue) > 0 && ctx.Err() == nil
+}
+
+// run keeps a stream open and drains the queue into it until ctx ends.
+// The stream is only opened once there is something to send, so servers
+// that don't implement an RPC aren't hammered unless it is actually used.
+func (o *outbox[T]) run(ctx context.Context) {
+	defer close(o.done)
+	defer o.setState(StateClosed)
+
+	if !o.waitForWork(ctx) {
+		return
+	}
+
+	backoff := o.opts.Backoff
+	first := true
+
+	for ctx.Err() == nil {
+		o.setState(StateConnecting)
+		stream, err := o.open(ctx)
+		if err == nil {
+			o.mu.Lock()
+			o.state = StateConnected
+			if !first {
+				o.reconnects++
+			}
+			o.mu.Unlock()
+			first = false
+			backoff.Reset()
+			log.Printf("Opened %s stream", o.name)
+
+			err = o.drain(ctx, stream)
+			stream.CloseAndRecv()
+			if err == nil {
+				return // Closed with the queue delivered
+			}
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		o.setState(StateDisconnected)
+		delay := backoff.Next()
+		log.Printf("%s stream lost (%v), retrying in %v", o.name, err, delay)
+		if !sleepCtx(ctx, delay) {
+			return
+		}
+	}
+}
+
+// drain sends queued messages until the stream fails, ctx ends or the
+// outbox is closed and empty, which returns nil. A message is only removed
+// from the queue once Send has accepted it.
+func (o *outbox[T]) drain(ctx context.Context, stream clientStream[T]) error {
+	for {
+		if !o.waitForWork(ctx) {
+			return ctx.Err()
+		}
+
+		o.mu.Lock()
+		o.dropExpiredLocked()
+		if len(o.queue) == 0 {
+			o.mu.Unlock()
+			continue
+		}
+		next := o.queue[0]
+		o.mu.Unlock()
+
+		if err := stream.Send(next.msg); err != nil {
+			return err
+		}
+
+		o.mu.Lock()
+		// push may have evicted entries while we were unlocked
+		if len(o.queue) > 0 && proto.Message(o.queue[0].msg) == proto.Message(next.msg) {
+			o.queue = o.queue[1:]
+		}
+		o.mu.Unlock()
+	}
+}
+
+func (o *outbox[T]) dropExpiredLocked() {
+	if o.opts.MaxAge <= 0 {
+		return
+	}
+	cutoff := time.Now().Add(-o.opts.MaxAge)
+	i := 0
+	for i < len(o.queue) && o.queue[i].enqueued.Before(cutoff) {
+		i++
+	}
+	if i > 0 {
+		o.dropped += int64(i)
+		o.queue = o.queue[i:]
+	}
+}
+
+// save writes unsent messages to the queue file
+func (o *outbox[T]) save() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	msgs := make([]T, len(o.queue))
+	for i, q := range o.queue {
+		msgs[i] = q.msg
+	}
+	return saveMessages(o.queueFile, o.name, msgs)
+}
+
+// load restores messages persisted by a previous run. Their age is taken
+// from the message timestamp, so stale ones expire immediately.
+func (o *outbox[T]) load() error {
+	msgs, err := loadMessages(o.queueFile, o.name, o.newMsg)
+	if err != nil {
+		return err
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for _, msg := range msgs {
+		o.queue = append(o.queue, queued[T]{msg: msg, enqueued: time.Unix(0, o.timestamp(msg))})
+	}
+	if o.opts.MaxQueue > 0 && len(o.queue) > o.opts.MaxQueue {
+		o.dropped += int64(len(o.queue) - o.opts.MaxQueue)
+		o.queue = o.queue[len(o.queue)-o.opts.MaxQueue:]
+	}
+	if len(o.queue) > 0 {
+		o.used = true
+	}
+	return nil
+}
+
+// saveMessages writes msgs as length-delimited protobuf, removing the file
+// when there is nothing to keep
+func saveMessages[T proto.Message](path, name string, msgs []T) error {
+	if len(msgs) == 0 {
+		err := os.Remove(path)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	for _, msg := range msgs {
+		if _, err := protodelim.MarshalTo(w, msg); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	log.Printf("Persisted %d unsent %s messages to %s", len(msgs), name, path)
+	return f.Close()
+}
+
+// loadMessages reads a file written by saveMessages. A missing file is empty.
+func loadMessages[T proto.Message](path, name string, newMsg func() T) ([]T, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	msgs := make([]T, 0)
+	r := bufio.NewReader(f)
+	for {
+		msg := newMsg()
+		if err := protodelim.UnmarshalFrom(r, msg); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return msgs, err
+		}
+		msgs = append(msgs, msg)
+	}
+	log.Printf("Restored %d unsent %s messages from %s", len(msgs), name, path)
+	return msgs, nil
+}
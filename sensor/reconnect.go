@@ -0,0 +1,65 @@
+package sensor
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// ConnState is the health of a connection to a server
+type ConnState string
+
+const (
+	StateConnecting   ConnState = "connecting"
+	StateConnected    ConnState = "connected"
+	StateDisconnected ConnState = "disconnected" // Waiting out a backoff before retrying
+	StateClosed       ConnState = "closed"
+)
+
+// Backoff produces exponentially growing retry delays with jitter
+type Backoff struct {
+	Initial    time.Duration
+	Max        time.Duration
+	Multiplier float64
+	current    time.Duration
+}
+
+func DefaultBackoff() Backoff {
+	return Backoff{
+		Initial:    250 * time.Millisecond,
+		Max:        10 * time.Second,
+		Multiplier: 2,
+	}
+}
+
+// Next returns the delay before the next attempt and grows the backoff
+func (b *Backoff) Next() time.Duration {
+	if b.current == 0 {
+		b.current = b.Initial
+	} else {
+		b.current = time.Duration(float64(b.current) * b.Multiplier)
+		if b.current > b.Max {
+			b.current = b.Max
+		}
+	}
+	// +/- 20% jitter so a fleet doesn't reconnect in lockstep
+	jitter := 0.8 + rand.Float64()*0.4
+	return time.Duration(float64(b.current) * jitter)
+}
+
+// Reset starts the backoff from Initial again after a successful connection
+func (b *Backoff) Reset() {
+	b.current = 0
+}
+
+// sleepCtx waits for d, returning false if ctx was cancelled first
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,87 @@
+package sensor
+
+import (
+	"context"
+	"log"
+	"time"
+
+	sensorpb "distributed-sensor-fusion/shared/generated/sensorpb"
+)
+
+// Registration describes the sensor to the command server
+func (s *Sensor) Registration() *sensorpb.SensorRegistration {
+	model := s.NoiseConfig.Model
+	if model == "" {
+		model = NoiseWhite
+	}
+	return &sensorpb.SensorRegistration{
+		SensorId:       s.ID,
+		SensorType:     s.Type,
+		X:              s.Coverage.X,
+		Y:              s.Coverage.Y,
+		CoverageRadius: s.Coverage.Radius,
+		NoiseModel:     string(model),
+		PositionStdDev: s.NoiseConfig.PositionStdDev,
+		MissRate:       s.NoiseConfig.MissRate,
+		FalseAlarmRate: s.NoiseConfig.FalsePositiveRate,
+	}
+}
+
+// KeepRegistered registers the sensor with the command server and sends
+// heartbeats at the interval the server asks for, re-registering whenever
+// the server has forgotten it (e.g. after a restart). It runs until ctx is
+// cancelled; call it in its own goroutine after Connect.
+func (c *CommandClient) KeepRegistered(ctx context.Context, reg *sensorpb.SensorRegistration) {
+	client := sensorpb.NewSensorServiceClient(c.conn)
+	backoff := c.opts.Backoff
+
+	for ctx.Err() == nil {
+		resp, err := client.RegisterSensor(ctx, reg)
+		if err != nil || !resp.Accepted {
+			delay := backoff.Next()
+			if err == nil {
+				log.Printf("[%s] Registration rejected, retrying in %v", reg.SensorId, delay)
+			} else {
+				log.Printf("[%s] Registration failed (%v), retrying in %v", reg.SensorId, err, delay)
+			}
+			if !sleepCtx(ctx, delay) {
+				return
+			}
+			continue
+		}
+		backoff.Reset()
+		log.Printf("[%s] Registered with command server", reg.SensorId)
+
+		interval := time.Duration(resp.HeartbeatIntervalMs) * time.Millisecond
+		if interval <= 0 {
+			interval = time.Second
+		}
+		c.heartbeat(ctx, client, reg.SensorId, interval)
+	}
+}
+
+// heartbeat pings until the server stops recognising the sensor or ctx ends.
+// Transport errors are ridden out; the server times us out if they persist.
+func (c *CommandClient) heartbeat(ctx context.Context, client sensorpb.SensorServiceClient, sensorID string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			resp, err := client.Heartbeat(ctx, &sensorpb.HeartbeatRequest{
+				SensorId:  sensorID,
+				Timestamp: time.Now().UnixNano(),
+			})
+			if err != nil {
+				continue
+			}
+			if !resp.Known {
+				log.Printf("[%s] Command server lost our registration", sensorID)
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package sensor
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	worldpb "distributed-sensor-fusion/shared/generated/worldpb"
+)
+
+// ScanSchedule sets when a sensor looks at the world. With a zero Period
+// the sensor scans every world state as it arrives. Otherwise it revisits
+// every Period, offset by Phase from a grid anchored at the Unix epoch (so
+// sensors in different processes with the same schedule scan together),
+// sampling the latest world state it has received.
+type ScanSchedule struct {
+	Period time.Duration
+	Phase  time.Duration
+
+	// Advance truth positions along their velocities by the time elapsed
+	// since the world state arrived, instead of using them as they were
+	Interpolate bool
+}
+
+// latestWorld is the most recent world state and when it arrived, plus
+// the spacing of arrivals, which estimates the world tick period
+type latestWorld struct {
+	mu      sync.Mutex
+	state   *worldpb.WorldState
+	arrived time.Time
+	tick    time.Duration
+}
+
+func (l *latestWorld) store(state *worldpb.WorldState) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if l.state != nil {
+		if state.Tick != l.state.Tick {
+			l.tick = now.Sub(l.arrived) / time.Duration(max(state.Tick-l.state.Tick, 1))
+		} else {
+			l.tick = 0 // Paused: nothing to interpolate
+		}
+	}
+	l.state = state
+	l.arrived = now
+}
+
+func (l *latestWorld) load() (*worldpb.WorldState, time.Time, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.state, l.arrived, l.tick
+}
+
+// RunScans drives the sensor's own scan schedule until ctx is cancelled.
+// Start runs it; callers feeding Observe from a shared subscription must
+// run it themselves. It returns at once if the sensor scans every state.
+// Without interpolation a scan that would see the same state as the last
+// one is skipped, so scanning faster than the world ticks gains nothing.
+func (s *Sensor) RunScans(ctx context.Context) {
+	period := s.Schedule.Period
+	if period <= 0 {
+		return
+	}
+
+	var last *worldpb.WorldState
+	for {
+		now := time.Now().UnixNano()
+		phase := int64(s.Schedule.Phase % period)
+		next := (now-phase)/int64(period)*int64(period) + int64(period) + phase
+		if !sleepCtx(ctx, time.Duration(next-now)) {
+			return
+		}
+
+		state, arrived, tick := s.latest.load()
+		if state == nil || state == last && !s.Schedule.Interpolate {
+			continue
+		}
+		last = state
+
+		at := float64(state.Tick)
+		if s.Schedule.Interpolate {
+			var frac float64
+			state, frac = interpolate(state, time.Since(arrived), tick)
+			at += frac
+		}
+		s.processWorldState(state, at)
+	}
+}
+
+// interpolate advances every threat by the fraction of a world tick that
+// has passed since the state arrived, returning the fraction. World
+// velocities are per tick; the advance stops at one tick, since a newer
+// state should be along by then. Threats reaching a bounded world's edge
+// come back off it.
+func interpolate(state *worldpb.WorldState, since, tick time.Duration) (*worldpb.WorldState, float64) {
+	if tick <= 0 || since <= 0 {
+		return state, 0
+	}
+	frac := min(float64(since)/float64(tick), 1)
+
+	g := GeometryOf(state)
+	sampled := proto.Clone(state).(*worldpb.WorldState)
+	for _, t := range sampled.Threats {
+		var flipX, flipY bool
+		t.X, flipX = g.bounce(t.X, t.Vx*frac, g.Width)
+		t.Y, flipY = g.bounce(t.Y, t.Vy*frac, g.Height)
+		if flipX {
+			t.Vx = -t.Vx
+		}
+		if flipY {
+			t.Vy = -t.Vy
+		}
+	}
+	return sampled, frac
+}
@@ -0,0 +1,80 @@
+package sensor
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// TimingConfig simulates an imperfect sensor clock and link. The clock
+// reads true time plus ClockOffset plus ClockDrift times the time since
+// the sensor started; each report is then held back by a random latency,
+// so reports can arrive late and out of order. Zero values are ideal.
+type TimingConfig struct {
+	ClockOffset    float64 `json:"clock_offset"`     // Seconds the sensor clock is ahead (negative: behind)
+	ClockDrift     float64 `json:"clock_drift"`      // Parts per million the clock gains
+	Latency        float64 `json:"latency"`          // Seconds of base reporting delay
+	LatencyJitter  float64 `json:"latency_jitter"`   // Standard deviation of extra delay, seconds
+	LargeDelayRate float64 `json:"large_delay_rate"` // Probability a report is badly delayed
+	LargeDelay     float64 `json:"large_delay"`      // Mean of the exponential extra delay when it is, seconds
+}
+
+// RegisterFlags binds the timing settings to command line flags
+func (tc *TimingConfig) RegisterFlags(fs *flag.FlagSet) {
+	fs.Float64Var(&tc.ClockOffset, "clock-offset", tc.ClockOffset, "timing: seconds the sensor clock is ahead of true time")
+	fs.Float64Var(&tc.ClockDrift, "clock-drift", tc.ClockDrift, "timing: clock drift in parts per million")
+	fs.Float64Var(&tc.Latency, "latency", tc.Latency, "timing: base reporting delay in seconds")
+	fs.Float64Var(&tc.LatencyJitter, "latency-jitter", tc.LatencyJitter, "timing: standard deviation of extra delay in seconds")
+	fs.Float64Var(&tc.LargeDelayRate, "large-delay-rate", tc.LargeDelayRate, "timing: probability of a badly delayed report")
+	fs.Float64Var(&tc.LargeDelay, "large-delay", tc.LargeDelay, "timing: mean extra delay of a badly delayed report in seconds")
+}
+
+// Enabled reports whether the clock or link is anything but ideal
+func (tc TimingConfig) Enabled() bool {
+	return tc.ClockOffset != 0 || tc.ClockDrift != 0 || tc.Latency > 0 ||
+		tc.LatencyJitter > 0 || (tc.LargeDelayRate > 0 && tc.LargeDelay > 0)
+}
+
+// Validate rejects settings that can't describe a clock or delay
+func (tc TimingConfig) Validate() error {
+	if tc.Latency < 0 || tc.LatencyJitter < 0 || tc.LargeDelay < 0 {
+		return fmt.Errorf("timing: latency, latency_jitter and large_delay must not be negative")
+	}
+	if tc.LargeDelayRate < 0 || tc.LargeDelayRate > 1 {
+		return fmt.Errorf("timing: large_delay_rate must be between 0 and 1")
+	}
+	return nil
+}
+
+// sensorClock is a skewed, drifting clock plus a latency generator
+type sensorClock struct {
+	cfg   TimingConfig
+	start time.Time
+}
+
+func newSensorClock(cfg TimingConfig) *sensorClock {
+	return &sensorClock{cfg: cfg, start: time.Now()}
+}
+
+// now returns the sensor's idea of the current time in Unix nanoseconds
+func (c *sensorClock) now() int64 {
+	return c.at(time.Now())
+}
+
+// at returns what the sensor's clock reads at true time t
+func (c *sensorClock) at(t time.Time) int64 {
+	elapsed := t.Sub(c.start).Seconds()
+	skew := c.cfg.ClockOffset + elapsed*c.cfg.ClockDrift*1e-6
+	return t.UnixNano() + int64(skew*float64(time.Second))
+}
+
+// latency draws how long a report spends getting to the command server
+func (c *sensorClock) latency() time.Duration {
+	delay := c.cfg.Latency + math.Abs(Gaussian(0, c.cfg.LatencyJitter))
+	if c.cfg.LargeDelay > 0 && rand.Float64() < c.cfg.LargeDelayRate {
+		delay += rand.ExpFloat64() * c.cfg.LargeDelay
+	}
+	return time.Duration(delay * float64(time.Second))
+}
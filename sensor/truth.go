@@ -0,0 +1,60 @@
+package sensor
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+)
+
+// ReadingOrigin says what actually caused a reading
+type ReadingOrigin string
+
+const (
+	OriginTarget  ReadingOrigin = "target"  // Detection of a real threat
+	OriginClutter ReadingOrigin = "clutter" // False alarm from the clutter model
+)
+
+// TruthRecord ties a sent reading back to ground truth. It travels on a
+// side channel so scoring tools can tell clutter apart without the
+// command server ever seeing it.
+type TruthRecord struct {
+	SensorID  string        `json:"sensor_id"`
+	Timestamp int64         `json:"timestamp"`
+	Origin    ReadingOrigin `json:"origin"`
+	ThreatID  int32         `json:"threat_id"` // Real threat ID (a ghost's source), -1 for clutter
+	X         float64       `json:"x"`
+	Y         float64       `json:"y"`
+	TrueX     float64       `json:"true_x"`
+	TrueY     float64       `json:"true_y"`
+	Bearing   float64       `json:"bearing,omitempty"` // Reported bearing, for bearing-only sensors
+	Arrival   int64         `json:"arrival,omitempty"` // Reported arrival time, for TDOA sensors
+}
+
+// Truth returns the ground truth side channel for this sensor's readings
+func (s *Sensor) Truth() <-chan TruthRecord {
+	return s.truth
+}
+
+func (s *Sensor) emitTruth(rec TruthRecord) {
+	select {
+	case s.truth <- rec:
+	default:
+		// Nobody scoring, drop silently
+	}
+}
+
+// RecordTruth writes the sensor's truth records to w as JSON lines until
+// ctx is cancelled, for scoring a run afterwards
+func (s *Sensor) RecordTruth(ctx context.Context, w io.Writer) error {
+	enc := json.NewEncoder(w)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case rec := <-s.Truth():
+			if err := enc.Encode(rec); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package sensor
+
+import (
+	"math"
+	"math/rand"
+
+	sensorpb "distributed-sensor-fusion/shared/generated/sensorpb"
+)
+
+type VelocityMode string
+
+const (
+	VelocityNone    VelocityMode = ""        // Position only
+	VelocityDoppler VelocityMode = "doppler" // Radial component along the line of sight
+	VelocityFull    VelocityMode = "full"    // Both components
+)
+
+// VelocityModel says whether and how well a sensor measures target motion
+type VelocityModel struct {
+	Mode   VelocityMode `json:"mode"`
+	StdDev float64      `json:"std_dev"` // Noise on each measured component, units per tick
+}
+
+// VelocityModelFor returns the motion measurement typical of a sensor type:
+// radars see Doppler, the others only position
+func VelocityModelFor(sensorType string) (VelocityModel, error) {
+	if err := ValidSensorType(sensorType); err != nil {
+		return VelocityModel{}, err
+	}
+	switch sensorType {
+	case "eo", "optical", "acoustic":
+		return VelocityModel{}, nil
+	default:
+		return VelocityModel{Mode: VelocityDoppler, StdDev: 0.1}, nil
+	}
+}
+
+// measureVelocity turns a target's true velocity into a noisy measurement
+// as seen from the sensor at (sx, sy). It returns nil if the sensor has no
+// velocity measurement or the direction to the target is undefined.
+func (vm VelocityModel) measureVelocity(sx, sy, x, y, vx, vy float64, g Geometry) *sensorpb.Velocity {
+	switch vm.Mode {
+	case VelocityFull:
+		return &sensorpb.Velocity{
+			Full:   true,
+			Vx:     vx + Gaussian(0, vm.StdDev),
+			Vy:     vy + Gaussian(0, vm.StdDev),
+			StdDev: vm.StdDev,
+		}
+	case VelocityDoppler:
+		dx := g.shortestDelta(sx, x, g.Width)
+		dy := g.shortestDelta(sy, y, g.Height)
+		r := math.Sqrt(dx*dx + dy*dy)
+		if r < 1e-6 {
+			return nil
+		}
+		return &sensorpb.Velocity{
+			Radial:  (vx*dx+vy*dy)/r + Gaussian(0, vm.StdDev),
+			SensorX: sx,
+			SensorY: sy,
+			StdDev:  vm.StdDev,
+		}
+	}
+	return nil
+}
+
+// clutterVelocity gives a false alarm a random but plausible velocity
+func (vm VelocityModel) clutterVelocity(sx, sy, x, y float64, g Geometry) *sensorpb.Velocity {
+	return vm.measureVelocity(sx, sy, x, y, rand.Float64()*4-2, rand.Float64()*4-2, g)
+}
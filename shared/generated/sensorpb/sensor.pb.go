@@ -22,33 +22,693 @@ const (
 )
 
 type SensorReading struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	SensorId   string                 `protobuf:"bytes,1,opt,name=sensor_id,json=sensorId,proto3" json:"sensor_id,omitempty"`
+	ThreatId   int32                  `protobuf:"varint,2,opt,name=threat_id,json=threatId,proto3" json:"threat_id,omitempty"`
+	X          float64                `protobuf:"fixed64,3,opt,name=x,proto3" json:"x,omitempty"`
+	Y          float64                `protobuf:"fixed64,4,opt,name=y,proto3" json:"y,omitempty"`
+	Level      int32                  `protobuf:"varint,5,opt,name=level,proto3" json:"level,omitempty"`
+	Timestamp  int64                  `protobuf:"varint,6,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Confidence float64                `protobuf:"fixed64,7,opt,name=confidence,proto3" json:"confidence,omitempty"`
+	// Set when the sensor suppresses reports while the target follows this model
+	DeadReckoning *DeadReckoning `protobuf:"bytes,8,opt,name=dead_reckoning,json=deadReckoning,proto3" json:"dead_reckoning,omitempty"`
+	// Set by sensors that measure target motion
+	Velocity      *Velocity `protobuf:"bytes,9,opt,name=velocity,proto3" json:"velocity,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SensorReading) Reset() {
+	*x = SensorReading{}
+	mi := &file_proto_sensor_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SensorReading) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SensorReading) ProtoMessage() {}
+
+func (x *SensorReading) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sensor_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SensorReading.ProtoReflect.Descriptor instead.
+func (*SensorReading) Descriptor() ([]byte, []int) {
+	return file_proto_sensor_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SensorReading) GetSensorId() string {
+	if x != nil {
+		return x.SensorId
+	}
+	return ""
+}
+
+func (x *SensorReading) GetThreatId() int32 {
+	if x != nil {
+		return x.ThreatId
+	}
+	return 0
+}
+
+func (x *SensorReading) GetX() float64 {
+	if x != nil {
+		return x.X
+	}
+	return 0
+}
+
+func (x *SensorReading) GetY() float64 {
+	if x != nil {
+		return x.Y
+	}
+	return 0
+}
+
+func (x *SensorReading) GetLevel() int32 {
+	if x != nil {
+		return x.Level
+	}
+	return 0
+}
+
+func (x *SensorReading) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *SensorReading) GetConfidence() float64 {
+	if x != nil {
+		return x.Confidence
+	}
+	return 0
+}
+
+func (x *SensorReading) GetDeadReckoning() *DeadReckoning {
+	if x != nil {
+		return x.DeadReckoning
+	}
+	return nil
+}
+
+func (x *SensorReading) GetVelocity() *Velocity {
+	if x != nil {
+		return x.Velocity
+	}
+	return nil
+}
+
+// Velocity is a velocity measurement in world units per tick, like the
+// world's threats. Doppler sensors only measure the radial component.
+type Velocity struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Full          bool                   `protobuf:"varint,1,opt,name=full,proto3" json:"full,omitempty"` // vx and vy are measured, not just radial
+	Vx            float64                `protobuf:"fixed64,2,opt,name=vx,proto3" json:"vx,omitempty"`
+	Vy            float64                `protobuf:"fixed64,3,opt,name=vy,proto3" json:"vy,omitempty"`
+	Radial        float64                `protobuf:"fixed64,4,opt,name=radial,proto3" json:"radial,omitempty"`                  // Speed away from the sensor along the line of sight
+	SensorX       float64                `protobuf:"fixed64,5,opt,name=sensor_x,json=sensorX,proto3" json:"sensor_x,omitempty"` // Where radial was measured from
+	SensorY       float64                `protobuf:"fixed64,6,opt,name=sensor_y,json=sensorY,proto3" json:"sensor_y,omitempty"`
+	StdDev        float64                `protobuf:"fixed64,7,opt,name=std_dev,json=stdDev,proto3" json:"std_dev,omitempty"` // Measurement noise of each component
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Velocity) Reset() {
+	*x = Velocity{}
+	mi := &file_proto_sensor_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Velocity) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Velocity) ProtoMessage() {}
+
+func (x *Velocity) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sensor_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Velocity.ProtoReflect.Descriptor instead.
+func (*Velocity) Descriptor() ([]byte, []int) {
+	return file_proto_sensor_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Velocity) GetFull() bool {
+	if x != nil {
+		return x.Full
+	}
+	return false
+}
+
+func (x *Velocity) GetVx() float64 {
+	if x != nil {
+		return x.Vx
+	}
+	return 0
+}
+
+func (x *Velocity) GetVy() float64 {
+	if x != nil {
+		return x.Vy
+	}
+	return 0
+}
+
+func (x *Velocity) GetRadial() float64 {
+	if x != nil {
+		return x.Radial
+	}
+	return 0
+}
+
+func (x *Velocity) GetSensorX() float64 {
+	if x != nil {
+		return x.SensorX
+	}
+	return 0
+}
+
+func (x *Velocity) GetSensorY() float64 {
+	if x != nil {
+		return x.SensorY
+	}
+	return 0
+}
+
+func (x *Velocity) GetStdDev() float64 {
+	if x != nil {
+		return x.StdDev
+	}
+	return 0
+}
+
+// DeadReckoning tells the receiver how to extrapolate a reading until the
+// next one arrives. Silence up to max_silence_ms means "as predicted".
+type DeadReckoning struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Velocity in world units per second
+	Vx            float64 `protobuf:"fixed64,1,opt,name=vx,proto3" json:"vx,omitempty"`
+	Vy            float64 `protobuf:"fixed64,2,opt,name=vy,proto3" json:"vy,omitempty"`
+	MaxSilenceMs  int64   `protobuf:"varint,3,opt,name=max_silence_ms,json=maxSilenceMs,proto3" json:"max_silence_ms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeadReckoning) Reset() {
+	*x = DeadReckoning{}
+	mi := &file_proto_sensor_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeadReckoning) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeadReckoning) ProtoMessage() {}
+
+func (x *DeadReckoning) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sensor_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeadReckoning.ProtoReflect.Descriptor instead.
+func (*DeadReckoning) Descriptor() ([]byte, []int) {
+	return file_proto_sensor_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *DeadReckoning) GetVx() float64 {
+	if x != nil {
+		return x.Vx
+	}
+	return 0
+}
+
+func (x *DeadReckoning) GetVy() float64 {
+	if x != nil {
+		return x.Vy
+	}
+	return 0
+}
+
+func (x *DeadReckoning) GetMaxSilenceMs() int64 {
+	if x != nil {
+		return x.MaxSilenceMs
+	}
+	return 0
+}
+
+// BearingReading is a passive sensor's detection: only the direction to
+// the target from the sensor's known position, in radians counter-clockwise
+// from the +x axis
+type BearingReading struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	SensorId      string                 `protobuf:"bytes,1,opt,name=sensor_id,json=sensorId,proto3" json:"sensor_id,omitempty"`
 	ThreatId      int32                  `protobuf:"varint,2,opt,name=threat_id,json=threatId,proto3" json:"threat_id,omitempty"`
-	X             float64                `protobuf:"fixed64,3,opt,name=x,proto3" json:"x,omitempty"`
-	Y             float64                `protobuf:"fixed64,4,opt,name=y,proto3" json:"y,omitempty"`
-	Level         int32                  `protobuf:"varint,5,opt,name=level,proto3" json:"level,omitempty"`
-	Timestamp     int64                  `protobuf:"varint,6,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
-	Confidence    float64                `protobuf:"fixed64,7,opt,name=confidence,proto3" json:"confidence,omitempty"`
+	SensorX       float64                `protobuf:"fixed64,3,opt,name=sensor_x,json=sensorX,proto3" json:"sensor_x,omitempty"`
+	SensorY       float64                `protobuf:"fixed64,4,opt,name=sensor_y,json=sensorY,proto3" json:"sensor_y,omitempty"`
+	Bearing       float64                `protobuf:"fixed64,5,opt,name=bearing,proto3" json:"bearing,omitempty"`
+	StdDev        float64                `protobuf:"fixed64,6,opt,name=std_dev,json=stdDev,proto3" json:"std_dev,omitempty"`
+	Level         int32                  `protobuf:"varint,7,opt,name=level,proto3" json:"level,omitempty"`
+	Timestamp     int64                  `protobuf:"varint,8,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Confidence    float64                `protobuf:"fixed64,9,opt,name=confidence,proto3" json:"confidence,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BearingReading) Reset() {
+	*x = BearingReading{}
+	mi := &file_proto_sensor_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BearingReading) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BearingReading) ProtoMessage() {}
+
+func (x *BearingReading) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sensor_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BearingReading.ProtoReflect.Descriptor instead.
+func (*BearingReading) Descriptor() ([]byte, []int) {
+	return file_proto_sensor_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *BearingReading) GetSensorId() string {
+	if x != nil {
+		return x.SensorId
+	}
+	return ""
+}
+
+func (x *BearingReading) GetThreatId() int32 {
+	if x != nil {
+		return x.ThreatId
+	}
+	return 0
+}
+
+func (x *BearingReading) GetSensorX() float64 {
+	if x != nil {
+		return x.SensorX
+	}
+	return 0
+}
+
+func (x *BearingReading) GetSensorY() float64 {
+	if x != nil {
+		return x.SensorY
+	}
+	return 0
+}
+
+func (x *BearingReading) GetBearing() float64 {
+	if x != nil {
+		return x.Bearing
+	}
+	return 0
+}
+
+func (x *BearingReading) GetStdDev() float64 {
+	if x != nil {
+		return x.StdDev
+	}
+	return 0
+}
+
+func (x *BearingReading) GetLevel() int32 {
+	if x != nil {
+		return x.Level
+	}
+	return 0
+}
+
+func (x *BearingReading) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *BearingReading) GetConfidence() float64 {
+	if x != nil {
+		return x.Confidence
+	}
+	return 0
+}
+
+// ArrivalReading is an acoustic sensor's time of arrival for one emission.
+// Every source emits once per world tick; the same emission reaches each
+// sensor at a different time, and the command server localises the source
+// from the differences. Pulse and signature identify the emission across
+// sensors. The signature is a ground truth shortcut: sensors copy the
+// source's true threat ID into it, where a real array would match emissions
+// by correlating their waveforms. The command server trusts it to group
+// arrivals, to keep a source's fused threat across emissions and to pick
+// between ambiguous fixes.
+type ArrivalReading struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SensorId      string                 `protobuf:"bytes,1,opt,name=sensor_id,json=sensorId,proto3" json:"sensor_id,omitempty"`
+	SensorX       float64                `protobuf:"fixed64,2,opt,name=sensor_x,json=sensorX,proto3" json:"sensor_x,omitempty"`
+	SensorY       float64                `protobuf:"fixed64,3,opt,name=sensor_y,json=sensorY,proto3" json:"sensor_y,omitempty"`
+	Pulse         int32                  `protobuf:"varint,4,opt,name=pulse,proto3" json:"pulse,omitempty"`                                // World tick of the emission
+	Signature     int32                  `protobuf:"varint,5,opt,name=signature,proto3" json:"signature,omitempty"`                        // Acoustic signature of the source
+	ArrivalTime   int64                  `protobuf:"varint,6,opt,name=arrival_time,json=arrivalTime,proto3" json:"arrival_time,omitempty"` // Unix nanoseconds, sensor clock
+	StdDev        float64                `protobuf:"fixed64,7,opt,name=std_dev,json=stdDev,proto3" json:"std_dev,omitempty"`               // Timing noise, seconds
+	Speed         float64                `protobuf:"fixed64,8,opt,name=speed,proto3" json:"speed,omitempty"`                               // Propagation speed, units per second
+	Level         int32                  `protobuf:"varint,9,opt,name=level,proto3" json:"level,omitempty"`
+	Timestamp     int64                  `protobuf:"varint,10,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Confidence    float64                `protobuf:"fixed64,11,opt,name=confidence,proto3" json:"confidence,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ArrivalReading) Reset() {
+	*x = ArrivalReading{}
+	mi := &file_proto_sensor_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ArrivalReading) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ArrivalReading) ProtoMessage() {}
+
+func (x *ArrivalReading) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sensor_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ArrivalReading.ProtoReflect.Descriptor instead.
+func (*ArrivalReading) Descriptor() ([]byte, []int) {
+	return file_proto_sensor_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ArrivalReading) GetSensorId() string {
+	if x != nil {
+		return x.SensorId
+	}
+	return ""
+}
+
+func (x *ArrivalReading) GetSensorX() float64 {
+	if x != nil {
+		return x.SensorX
+	}
+	return 0
+}
+
+func (x *ArrivalReading) GetSensorY() float64 {
+	if x != nil {
+		return x.SensorY
+	}
+	return 0
+}
+
+func (x *ArrivalReading) GetPulse() int32 {
+	if x != nil {
+		return x.Pulse
+	}
+	return 0
+}
+
+func (x *ArrivalReading) GetSignature() int32 {
+	if x != nil {
+		return x.Signature
+	}
+	return 0
+}
+
+func (x *ArrivalReading) GetArrivalTime() int64 {
+	if x != nil {
+		return x.ArrivalTime
+	}
+	return 0
+}
+
+func (x *ArrivalReading) GetStdDev() float64 {
+	if x != nil {
+		return x.StdDev
+	}
+	return 0
+}
+
+func (x *ArrivalReading) GetSpeed() float64 {
+	if x != nil {
+		return x.Speed
+	}
+	return 0
+}
+
+func (x *ArrivalReading) GetLevel() int32 {
+	if x != nil {
+		return x.Level
+	}
+	return 0
+}
+
+func (x *ArrivalReading) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *ArrivalReading) GetConfidence() float64 {
+	if x != nil {
+		return x.Confidence
+	}
+	return 0
+}
+
+// ReadingBatch carries several readings from one sensor. Sequence numbers
+// increase by one per batch within a session, so the server can ack each
+// batch and drop retransmitted duplicates.
+type ReadingBatch struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	SensorId string                 `protobuf:"bytes,1,opt,name=sensor_id,json=sensorId,proto3" json:"sensor_id,omitempty"`
+	// Random per client run, so a restarted sensor's sequences aren't
+	// mistaken for duplicates
+	SessionId     uint64           `protobuf:"varint,2,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Sequence      uint64           `protobuf:"varint,3,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	Readings      []*SensorReading `protobuf:"bytes,4,rep,name=readings,proto3" json:"readings,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReadingBatch) Reset() {
+	*x = ReadingBatch{}
+	mi := &file_proto_sensor_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReadingBatch) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReadingBatch) ProtoMessage() {}
+
+func (x *ReadingBatch) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sensor_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReadingBatch.ProtoReflect.Descriptor instead.
+func (*ReadingBatch) Descriptor() ([]byte, []int) {
+	return file_proto_sensor_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ReadingBatch) GetSensorId() string {
+	if x != nil {
+		return x.SensorId
+	}
+	return ""
+}
+
+func (x *ReadingBatch) GetSessionId() uint64 {
+	if x != nil {
+		return x.SessionId
+	}
+	return 0
+}
+
+func (x *ReadingBatch) GetSequence() uint64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+func (x *ReadingBatch) GetReadings() []*SensorReading {
+	if x != nil {
+		return x.Readings
+	}
+	return nil
+}
+
+type BatchAck struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	SensorId  string                 `protobuf:"bytes,1,opt,name=sensor_id,json=sensorId,proto3" json:"sensor_id,omitempty"`
+	SessionId uint64                 `protobuf:"varint,2,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Sequence  uint64                 `protobuf:"varint,3,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	// The batch had already been processed and was ignored
+	Duplicate     bool `protobuf:"varint,4,opt,name=duplicate,proto3" json:"duplicate,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchAck) Reset() {
+	*x = BatchAck{}
+	mi := &file_proto_sensor_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchAck) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchAck) ProtoMessage() {}
+
+func (x *BatchAck) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sensor_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchAck.ProtoReflect.Descriptor instead.
+func (*BatchAck) Descriptor() ([]byte, []int) {
+	return file_proto_sensor_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *BatchAck) GetSensorId() string {
+	if x != nil {
+		return x.SensorId
+	}
+	return ""
+}
+
+func (x *BatchAck) GetSessionId() uint64 {
+	if x != nil {
+		return x.SessionId
+	}
+	return 0
+}
+
+func (x *BatchAck) GetSequence() uint64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+func (x *BatchAck) GetDuplicate() bool {
+	if x != nil {
+		return x.Duplicate
+	}
+	return false
+}
+
+// SensorTrack is a sensor's own filtered estimate of a target, sent instead
+// of raw detections when the sensor runs local tracking
+type SensorTrack struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	SensorId     string                 `protobuf:"bytes,1,opt,name=sensor_id,json=sensorId,proto3" json:"sensor_id,omitempty"`
+	LocalTrackId int32                  `protobuf:"varint,2,opt,name=local_track_id,json=localTrackId,proto3" json:"local_track_id,omitempty"`
+	X            float64                `protobuf:"fixed64,3,opt,name=x,proto3" json:"x,omitempty"`
+	Y            float64                `protobuf:"fixed64,4,opt,name=y,proto3" json:"y,omitempty"`
+	Vx           float64                `protobuf:"fixed64,5,opt,name=vx,proto3" json:"vx,omitempty"`
+	Vy           float64                `protobuf:"fixed64,6,opt,name=vy,proto3" json:"vy,omitempty"`
+	// Row-major 4x4 covariance over [x, y, vx, vy]
+	Covariance    []float64 `protobuf:"fixed64,7,rep,packed,name=covariance,proto3" json:"covariance,omitempty"`
+	UpdateCount   int32     `protobuf:"varint,8,opt,name=update_count,json=updateCount,proto3" json:"update_count,omitempty"`
+	Level         int32     `protobuf:"varint,9,opt,name=level,proto3" json:"level,omitempty"`
+	Timestamp     int64     `protobuf:"varint,10,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Confidence    float64   `protobuf:"fixed64,11,opt,name=confidence,proto3" json:"confidence,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *SensorReading) Reset() {
-	*x = SensorReading{}
-	mi := &file_proto_sensor_proto_msgTypes[0]
+func (x *SensorTrack) Reset() {
+	*x = SensorTrack{}
+	mi := &file_proto_sensor_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SensorReading) String() string {
+func (x *SensorTrack) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SensorReading) ProtoMessage() {}
+func (*SensorTrack) ProtoMessage() {}
 
-func (x *SensorReading) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_sensor_proto_msgTypes[0]
+func (x *SensorTrack) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sensor_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -59,60 +719,347 @@ func (x *SensorReading) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SensorReading.ProtoReflect.Descriptor instead.
-func (*SensorReading) Descriptor() ([]byte, []int) {
-	return file_proto_sensor_proto_rawDescGZIP(), []int{0}
+// Deprecated: Use SensorTrack.ProtoReflect.Descriptor instead.
+func (*SensorTrack) Descriptor() ([]byte, []int) {
+	return file_proto_sensor_proto_rawDescGZIP(), []int{7}
 }
 
-func (x *SensorReading) GetSensorId() string {
+func (x *SensorTrack) GetSensorId() string {
 	if x != nil {
 		return x.SensorId
 	}
 	return ""
 }
 
-func (x *SensorReading) GetThreatId() int32 {
+func (x *SensorTrack) GetLocalTrackId() int32 {
 	if x != nil {
-		return x.ThreatId
+		return x.LocalTrackId
 	}
 	return 0
 }
 
-func (x *SensorReading) GetX() float64 {
+func (x *SensorTrack) GetX() float64 {
 	if x != nil {
 		return x.X
 	}
 	return 0
 }
 
-func (x *SensorReading) GetY() float64 {
+func (x *SensorTrack) GetY() float64 {
 	if x != nil {
 		return x.Y
 	}
 	return 0
 }
 
-func (x *SensorReading) GetLevel() int32 {
+func (x *SensorTrack) GetVx() float64 {
+	if x != nil {
+		return x.Vx
+	}
+	return 0
+}
+
+func (x *SensorTrack) GetVy() float64 {
+	if x != nil {
+		return x.Vy
+	}
+	return 0
+}
+
+func (x *SensorTrack) GetCovariance() []float64 {
+	if x != nil {
+		return x.Covariance
+	}
+	return nil
+}
+
+func (x *SensorTrack) GetUpdateCount() int32 {
+	if x != nil {
+		return x.UpdateCount
+	}
+	return 0
+}
+
+func (x *SensorTrack) GetLevel() int32 {
 	if x != nil {
 		return x.Level
 	}
 	return 0
 }
 
-func (x *SensorReading) GetTimestamp() int64 {
+func (x *SensorTrack) GetTimestamp() int64 {
 	if x != nil {
 		return x.Timestamp
 	}
 	return 0
 }
 
-func (x *SensorReading) GetConfidence() float64 {
+func (x *SensorTrack) GetConfidence() float64 {
 	if x != nil {
 		return x.Confidence
 	}
 	return 0
 }
 
+// SensorRegistration declares a sensor and what it can see
+type SensorRegistration struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	SensorId       string                 `protobuf:"bytes,1,opt,name=sensor_id,json=sensorId,proto3" json:"sensor_id,omitempty"`
+	SensorType     string                 `protobuf:"bytes,2,opt,name=sensor_type,json=sensorType,proto3" json:"sensor_type,omitempty"`
+	X              float64                `protobuf:"fixed64,3,opt,name=x,proto3" json:"x,omitempty"`
+	Y              float64                `protobuf:"fixed64,4,opt,name=y,proto3" json:"y,omitempty"`
+	CoverageRadius float64                `protobuf:"fixed64,5,opt,name=coverage_radius,json=coverageRadius,proto3" json:"coverage_radius,omitempty"` // 0 means the whole world
+	NoiseModel     string                 `protobuf:"bytes,6,opt,name=noise_model,json=noiseModel,proto3" json:"noise_model,omitempty"`
+	PositionStdDev float64                `protobuf:"fixed64,7,opt,name=position_std_dev,json=positionStdDev,proto3" json:"position_std_dev,omitempty"`
+	MissRate       float64                `protobuf:"fixed64,8,opt,name=miss_rate,json=missRate,proto3" json:"miss_rate,omitempty"`
+	FalseAlarmRate float64                `protobuf:"fixed64,9,opt,name=false_alarm_rate,json=falseAlarmRate,proto3" json:"false_alarm_rate,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *SensorRegistration) Reset() {
+	*x = SensorRegistration{}
+	mi := &file_proto_sensor_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SensorRegistration) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SensorRegistration) ProtoMessage() {}
+
+func (x *SensorRegistration) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sensor_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SensorRegistration.ProtoReflect.Descriptor instead.
+func (*SensorRegistration) Descriptor() ([]byte, []int) {
+	return file_proto_sensor_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *SensorRegistration) GetSensorId() string {
+	if x != nil {
+		return x.SensorId
+	}
+	return ""
+}
+
+func (x *SensorRegistration) GetSensorType() string {
+	if x != nil {
+		return x.SensorType
+	}
+	return ""
+}
+
+func (x *SensorRegistration) GetX() float64 {
+	if x != nil {
+		return x.X
+	}
+	return 0
+}
+
+func (x *SensorRegistration) GetY() float64 {
+	if x != nil {
+		return x.Y
+	}
+	return 0
+}
+
+func (x *SensorRegistration) GetCoverageRadius() float64 {
+	if x != nil {
+		return x.CoverageRadius
+	}
+	return 0
+}
+
+func (x *SensorRegistration) GetNoiseModel() string {
+	if x != nil {
+		return x.NoiseModel
+	}
+	return ""
+}
+
+func (x *SensorRegistration) GetPositionStdDev() float64 {
+	if x != nil {
+		return x.PositionStdDev
+	}
+	return 0
+}
+
+func (x *SensorRegistration) GetMissRate() float64 {
+	if x != nil {
+		return x.MissRate
+	}
+	return 0
+}
+
+func (x *SensorRegistration) GetFalseAlarmRate() float64 {
+	if x != nil {
+		return x.FalseAlarmRate
+	}
+	return 0
+}
+
+type RegisterResponse struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Accepted bool                   `protobuf:"varint,1,opt,name=accepted,proto3" json:"accepted,omitempty"`
+	// How often the server expects a heartbeat
+	HeartbeatIntervalMs int64 `protobuf:"varint,2,opt,name=heartbeat_interval_ms,json=heartbeatIntervalMs,proto3" json:"heartbeat_interval_ms,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *RegisterResponse) Reset() {
+	*x = RegisterResponse{}
+	mi := &file_proto_sensor_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegisterResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegisterResponse) ProtoMessage() {}
+
+func (x *RegisterResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sensor_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegisterResponse.ProtoReflect.Descriptor instead.
+func (*RegisterResponse) Descriptor() ([]byte, []int) {
+	return file_proto_sensor_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *RegisterResponse) GetAccepted() bool {
+	if x != nil {
+		return x.Accepted
+	}
+	return false
+}
+
+func (x *RegisterResponse) GetHeartbeatIntervalMs() int64 {
+	if x != nil {
+		return x.HeartbeatIntervalMs
+	}
+	return 0
+}
+
+type HeartbeatRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SensorId      string                 `protobuf:"bytes,1,opt,name=sensor_id,json=sensorId,proto3" json:"sensor_id,omitempty"`
+	Timestamp     int64                  `protobuf:"varint,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HeartbeatRequest) Reset() {
+	*x = HeartbeatRequest{}
+	mi := &file_proto_sensor_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HeartbeatRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HeartbeatRequest) ProtoMessage() {}
+
+func (x *HeartbeatRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sensor_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HeartbeatRequest.ProtoReflect.Descriptor instead.
+func (*HeartbeatRequest) Descriptor() ([]byte, []int) {
+	return file_proto_sensor_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *HeartbeatRequest) GetSensorId() string {
+	if x != nil {
+		return x.SensorId
+	}
+	return ""
+}
+
+func (x *HeartbeatRequest) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+type HeartbeatResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// False if the server doesn't know this sensor and it should re-register
+	Known         bool `protobuf:"varint,1,opt,name=known,proto3" json:"known,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HeartbeatResponse) Reset() {
+	*x = HeartbeatResponse{}
+	mi := &file_proto_sensor_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HeartbeatResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HeartbeatResponse) ProtoMessage() {}
+
+func (x *HeartbeatResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sensor_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HeartbeatResponse.ProtoReflect.Descriptor instead.
+func (*HeartbeatResponse) Descriptor() ([]byte, []int) {
+	return file_proto_sensor_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *HeartbeatResponse) GetKnown() bool {
+	if x != nil {
+		return x.Known
+	}
+	return false
+}
+
 type StreamRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	SensorId      string                 `protobuf:"bytes,1,opt,name=sensor_id,json=sensorId,proto3" json:"sensor_id,omitempty"`
@@ -122,7 +1069,7 @@ type StreamRequest struct {
 
 func (x *StreamRequest) Reset() {
 	*x = StreamRequest{}
-	mi := &file_proto_sensor_proto_msgTypes[1]
+	mi := &file_proto_sensor_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -134,7 +1081,7 @@ func (x *StreamRequest) String() string {
 func (*StreamRequest) ProtoMessage() {}
 
 func (x *StreamRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_sensor_proto_msgTypes[1]
+	mi := &file_proto_sensor_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -147,7 +1094,7 @@ func (x *StreamRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StreamRequest.ProtoReflect.Descriptor instead.
 func (*StreamRequest) Descriptor() ([]byte, []int) {
-	return file_proto_sensor_proto_rawDescGZIP(), []int{1}
+	return file_proto_sensor_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *StreamRequest) GetSensorId() string {
@@ -166,7 +1113,7 @@ type Ack struct {
 
 func (x *Ack) Reset() {
 	*x = Ack{}
-	mi := &file_proto_sensor_proto_msgTypes[2]
+	mi := &file_proto_sensor_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -178,7 +1125,7 @@ func (x *Ack) String() string {
 func (*Ack) ProtoMessage() {}
 
 func (x *Ack) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_sensor_proto_msgTypes[2]
+	mi := &file_proto_sensor_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -191,7 +1138,7 @@ func (x *Ack) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Ack.ProtoReflect.Descriptor instead.
 func (*Ack) Descriptor() ([]byte, []int) {
-	return file_proto_sensor_proto_rawDescGZIP(), []int{2}
+	return file_proto_sensor_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *Ack) GetReceived() bool {
@@ -205,7 +1152,7 @@ var File_proto_sensor_proto protoreflect.FileDescriptor
 
 const file_proto_sensor_proto_rawDesc = "" +
 	"\n" +
-	"\x12proto/sensor.proto\x12\x06sensor\"\xb9\x01\n" +
+	"\x12proto/sensor.proto\x12\x06sensor\"\xa5\x02\n" +
 	"\rSensorReading\x12\x1b\n" +
 	"\tsensor_id\x18\x01 \x01(\tR\bsensorId\x12\x1b\n" +
 	"\tthreat_id\x18\x02 \x01(\x05R\bthreatId\x12\f\n" +
@@ -215,13 +1162,109 @@ const file_proto_sensor_proto_rawDesc = "" +
 	"\ttimestamp\x18\x06 \x01(\x03R\ttimestamp\x12\x1e\n" +
 	"\n" +
 	"confidence\x18\a \x01(\x01R\n" +
-	"confidence\",\n" +
+	"confidence\x12<\n" +
+	"\x0edead_reckoning\x18\b \x01(\v2\x15.sensor.DeadReckoningR\rdeadReckoning\x12,\n" +
+	"\bvelocity\x18\t \x01(\v2\x10.sensor.VelocityR\bvelocity\"\xa5\x01\n" +
+	"\bVelocity\x12\x12\n" +
+	"\x04full\x18\x01 \x01(\bR\x04full\x12\x0e\n" +
+	"\x02vx\x18\x02 \x01(\x01R\x02vx\x12\x0e\n" +
+	"\x02vy\x18\x03 \x01(\x01R\x02vy\x12\x16\n" +
+	"\x06radial\x18\x04 \x01(\x01R\x06radial\x12\x19\n" +
+	"\bsensor_x\x18\x05 \x01(\x01R\asensorX\x12\x19\n" +
+	"\bsensor_y\x18\x06 \x01(\x01R\asensorY\x12\x17\n" +
+	"\astd_dev\x18\a \x01(\x01R\x06stdDev\"U\n" +
+	"\rDeadReckoning\x12\x0e\n" +
+	"\x02vx\x18\x01 \x01(\x01R\x02vx\x12\x0e\n" +
+	"\x02vy\x18\x02 \x01(\x01R\x02vy\x12$\n" +
+	"\x0emax_silence_ms\x18\x03 \x01(\x03R\fmaxSilenceMs\"\x87\x02\n" +
+	"\x0eBearingReading\x12\x1b\n" +
+	"\tsensor_id\x18\x01 \x01(\tR\bsensorId\x12\x1b\n" +
+	"\tthreat_id\x18\x02 \x01(\x05R\bthreatId\x12\x19\n" +
+	"\bsensor_x\x18\x03 \x01(\x01R\asensorX\x12\x19\n" +
+	"\bsensor_y\x18\x04 \x01(\x01R\asensorY\x12\x18\n" +
+	"\abearing\x18\x05 \x01(\x01R\abearing\x12\x17\n" +
+	"\astd_dev\x18\x06 \x01(\x01R\x06stdDev\x12\x14\n" +
+	"\x05level\x18\a \x01(\x05R\x05level\x12\x1c\n" +
+	"\ttimestamp\x18\b \x01(\x03R\ttimestamp\x12\x1e\n" +
+	"\n" +
+	"confidence\x18\t \x01(\x01R\n" +
+	"confidence\"\xbd\x02\n" +
+	"\x0eArrivalReading\x12\x1b\n" +
+	"\tsensor_id\x18\x01 \x01(\tR\bsensorId\x12\x19\n" +
+	"\bsensor_x\x18\x02 \x01(\x01R\asensorX\x12\x19\n" +
+	"\bsensor_y\x18\x03 \x01(\x01R\asensorY\x12\x14\n" +
+	"\x05pulse\x18\x04 \x01(\x05R\x05pulse\x12\x1c\n" +
+	"\tsignature\x18\x05 \x01(\x05R\tsignature\x12!\n" +
+	"\farrival_time\x18\x06 \x01(\x03R\varrivalTime\x12\x17\n" +
+	"\astd_dev\x18\a \x01(\x01R\x06stdDev\x12\x14\n" +
+	"\x05speed\x18\b \x01(\x01R\x05speed\x12\x14\n" +
+	"\x05level\x18\t \x01(\x05R\x05level\x12\x1c\n" +
+	"\ttimestamp\x18\n" +
+	" \x01(\x03R\ttimestamp\x12\x1e\n" +
+	"\n" +
+	"confidence\x18\v \x01(\x01R\n" +
+	"confidence\"\x99\x01\n" +
+	"\fReadingBatch\x12\x1b\n" +
+	"\tsensor_id\x18\x01 \x01(\tR\bsensorId\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x02 \x01(\x04R\tsessionId\x12\x1a\n" +
+	"\bsequence\x18\x03 \x01(\x04R\bsequence\x121\n" +
+	"\breadings\x18\x04 \x03(\v2\x15.sensor.SensorReadingR\breadings\"\x80\x01\n" +
+	"\bBatchAck\x12\x1b\n" +
+	"\tsensor_id\x18\x01 \x01(\tR\bsensorId\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x02 \x01(\x04R\tsessionId\x12\x1a\n" +
+	"\bsequence\x18\x03 \x01(\x04R\bsequence\x12\x1c\n" +
+	"\tduplicate\x18\x04 \x01(\bR\tduplicate\"\xa3\x02\n" +
+	"\vSensorTrack\x12\x1b\n" +
+	"\tsensor_id\x18\x01 \x01(\tR\bsensorId\x12$\n" +
+	"\x0elocal_track_id\x18\x02 \x01(\x05R\flocalTrackId\x12\f\n" +
+	"\x01x\x18\x03 \x01(\x01R\x01x\x12\f\n" +
+	"\x01y\x18\x04 \x01(\x01R\x01y\x12\x0e\n" +
+	"\x02vx\x18\x05 \x01(\x01R\x02vx\x12\x0e\n" +
+	"\x02vy\x18\x06 \x01(\x01R\x02vy\x12\x1e\n" +
+	"\n" +
+	"covariance\x18\a \x03(\x01R\n" +
+	"covariance\x12!\n" +
+	"\fupdate_count\x18\b \x01(\x05R\vupdateCount\x12\x14\n" +
+	"\x05level\x18\t \x01(\x05R\x05level\x12\x1c\n" +
+	"\ttimestamp\x18\n" +
+	" \x01(\x03R\ttimestamp\x12\x1e\n" +
+	"\n" +
+	"confidence\x18\v \x01(\x01R\n" +
+	"confidence\"\xa9\x02\n" +
+	"\x12SensorRegistration\x12\x1b\n" +
+	"\tsensor_id\x18\x01 \x01(\tR\bsensorId\x12\x1f\n" +
+	"\vsensor_type\x18\x02 \x01(\tR\n" +
+	"sensorType\x12\f\n" +
+	"\x01x\x18\x03 \x01(\x01R\x01x\x12\f\n" +
+	"\x01y\x18\x04 \x01(\x01R\x01y\x12'\n" +
+	"\x0fcoverage_radius\x18\x05 \x01(\x01R\x0ecoverageRadius\x12\x1f\n" +
+	"\vnoise_model\x18\x06 \x01(\tR\n" +
+	"noiseModel\x12(\n" +
+	"\x10position_std_dev\x18\a \x01(\x01R\x0epositionStdDev\x12\x1b\n" +
+	"\tmiss_rate\x18\b \x01(\x01R\bmissRate\x12(\n" +
+	"\x10false_alarm_rate\x18\t \x01(\x01R\x0efalseAlarmRate\"b\n" +
+	"\x10RegisterResponse\x12\x1a\n" +
+	"\baccepted\x18\x01 \x01(\bR\baccepted\x122\n" +
+	"\x15heartbeat_interval_ms\x18\x02 \x01(\x03R\x13heartbeatIntervalMs\"M\n" +
+	"\x10HeartbeatRequest\x12\x1b\n" +
+	"\tsensor_id\x18\x01 \x01(\tR\bsensorId\x12\x1c\n" +
+	"\ttimestamp\x18\x02 \x01(\x03R\ttimestamp\")\n" +
+	"\x11HeartbeatResponse\x12\x14\n" +
+	"\x05known\x18\x01 \x01(\bR\x05known\",\n" +
 	"\rStreamRequest\x12\x1b\n" +
 	"\tsensor_id\x18\x01 \x01(\tR\bsensorId\"!\n" +
 	"\x03Ack\x12\x1a\n" +
-	"\breceived\x18\x01 \x01(\bR\breceived2G\n" +
+	"\breceived\x18\x01 \x01(\bR\breceived2\xbb\x03\n" +
 	"\rSensorService\x126\n" +
-	"\x0eStreamReadings\x12\x15.sensor.SensorReading\x1a\v.sensor.Ack(\x01B5Z3distributed-sensor-fusion/shared/generated/sensorpbb\x06proto3"
+	"\x0eStreamReadings\x12\x15.sensor.SensorReading\x1a\v.sensor.Ack(\x01\x12B\n" +
+	"\x14StreamReadingBatches\x12\x14.sensor.ReadingBatch\x1a\x10.sensor.BatchAck(\x010\x01\x122\n" +
+	"\fStreamTracks\x12\x13.sensor.SensorTrack\x1a\v.sensor.Ack(\x01\x127\n" +
+	"\x0eStreamBearings\x12\x16.sensor.BearingReading\x1a\v.sensor.Ack(\x01\x127\n" +
+	"\x0eStreamArrivals\x12\x16.sensor.ArrivalReading\x1a\v.sensor.Ack(\x01\x12F\n" +
+	"\x0eRegisterSensor\x12\x1a.sensor.SensorRegistration\x1a\x18.sensor.RegisterResponse\x12@\n" +
+	"\tHeartbeat\x12\x18.sensor.HeartbeatRequest\x1a\x19.sensor.HeartbeatResponseB5Z3distributed-sensor-fusion/shared/generated/sensorpbb\x06proto3"
 
 var (
 	file_proto_sensor_proto_rawDescOnce sync.Once
@@ -235,20 +1278,46 @@ func file_proto_sensor_proto_rawDescGZIP() []byte {
 	return file_proto_sensor_proto_rawDescData
 }
 
-var file_proto_sensor_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_proto_sensor_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
 var file_proto_sensor_proto_goTypes = []any{
-	(*SensorReading)(nil), // 0: sensor.SensorReading
-	(*StreamRequest)(nil), // 1: sensor.StreamRequest
-	(*Ack)(nil),           // 2: sensor.Ack
+	(*SensorReading)(nil),      // 0: sensor.SensorReading
+	(*Velocity)(nil),           // 1: sensor.Velocity
+	(*DeadReckoning)(nil),      // 2: sensor.DeadReckoning
+	(*BearingReading)(nil),     // 3: sensor.BearingReading
+	(*ArrivalReading)(nil),     // 4: sensor.ArrivalReading
+	(*ReadingBatch)(nil),       // 5: sensor.ReadingBatch
+	(*BatchAck)(nil),           // 6: sensor.BatchAck
+	(*SensorTrack)(nil),        // 7: sensor.SensorTrack
+	(*SensorRegistration)(nil), // 8: sensor.SensorRegistration
+	(*RegisterResponse)(nil),   // 9: sensor.RegisterResponse
+	(*HeartbeatRequest)(nil),   // 10: sensor.HeartbeatRequest
+	(*HeartbeatResponse)(nil),  // 11: sensor.HeartbeatResponse
+	(*StreamRequest)(nil),      // 12: sensor.StreamRequest
+	(*Ack)(nil),                // 13: sensor.Ack
 }
 var file_proto_sensor_proto_depIdxs = []int32{
-	0, // 0: sensor.SensorService.StreamReadings:input_type -> sensor.SensorReading
-	2, // 1: sensor.SensorService.StreamReadings:output_type -> sensor.Ack
-	1, // [1:2] is the sub-list for method output_type
-	0, // [0:1] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	2,  // 0: sensor.SensorReading.dead_reckoning:type_name -> sensor.DeadReckoning
+	1,  // 1: sensor.SensorReading.velocity:type_name -> sensor.Velocity
+	0,  // 2: sensor.ReadingBatch.readings:type_name -> sensor.SensorReading
+	0,  // 3: sensor.SensorService.StreamReadings:input_type -> sensor.SensorReading
+	5,  // 4: sensor.SensorService.StreamReadingBatches:input_type -> sensor.ReadingBatch
+	7,  // 5: sensor.SensorService.StreamTracks:input_type -> sensor.SensorTrack
+	3,  // 6: sensor.SensorService.StreamBearings:input_type -> sensor.BearingReading
+	4,  // 7: sensor.SensorService.StreamArrivals:input_type -> sensor.ArrivalReading
+	8,  // 8: sensor.SensorService.RegisterSensor:input_type -> sensor.SensorRegistration
+	10, // 9: sensor.SensorService.Heartbeat:input_type -> sensor.HeartbeatRequest
+	13, // 10: sensor.SensorService.StreamReadings:output_type -> sensor.Ack
+	6,  // 11: sensor.SensorService.StreamReadingBatches:output_type -> sensor.BatchAck
+	13, // 12: sensor.SensorService.StreamTracks:output_type -> sensor.Ack
+	13, // 13: sensor.SensorService.StreamBearings:output_type -> sensor.Ack
+	13, // 14: sensor.SensorService.StreamArrivals:output_type -> sensor.Ack
+	9,  // 15: sensor.SensorService.RegisterSensor:output_type -> sensor.RegisterResponse
+	11, // 16: sensor.SensorService.Heartbeat:output_type -> sensor.HeartbeatResponse
+	10, // [10:17] is the sub-list for method output_type
+	3,  // [3:10] is the sub-list for method input_type
+	3,  // [3:3] is the sub-list for extension type_name
+	3,  // [3:3] is the sub-list for extension extendee
+	0,  // [0:3] is the sub-list for field type_name
 }
 
 func init() { file_proto_sensor_proto_init() }
@@ -262,7 +1331,7 @@ func file_proto_sensor_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_sensor_proto_rawDesc), len(file_proto_sensor_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   3,
+			NumMessages:   14,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -19,7 +19,13 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	SensorService_StreamReadings_FullMethodName = "/sensor.SensorService/StreamReadings"
+	SensorService_StreamReadings_FullMethodName       = "/sensor.SensorService/StreamReadings"
+	SensorService_StreamReadingBatches_FullMethodName = "/sensor.SensorService/StreamReadingBatches"
+	SensorService_StreamTracks_FullMethodName         = "/sensor.SensorService/StreamTracks"
+	SensorService_StreamBearings_FullMethodName       = "/sensor.SensorService/StreamBearings"
+	SensorService_StreamArrivals_FullMethodName       = "/sensor.SensorService/StreamArrivals"
+	SensorService_RegisterSensor_FullMethodName       = "/sensor.SensorService/RegisterSensor"
+	SensorService_Heartbeat_FullMethodName            = "/sensor.SensorService/Heartbeat"
 )
 
 // SensorServiceClient is the client API for SensorService service.
@@ -27,6 +33,12 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type SensorServiceClient interface {
 	StreamReadings(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[SensorReading, Ack], error)
+	StreamReadingBatches(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[ReadingBatch, BatchAck], error)
+	StreamTracks(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[SensorTrack, Ack], error)
+	StreamBearings(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[BearingReading, Ack], error)
+	StreamArrivals(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[ArrivalReading, Ack], error)
+	RegisterSensor(ctx context.Context, in *SensorRegistration, opts ...grpc.CallOption) (*RegisterResponse, error)
+	Heartbeat(ctx context.Context, in *HeartbeatRequest, opts ...grpc.CallOption) (*HeartbeatResponse, error)
 }
 
 type sensorServiceClient struct {
@@ -50,11 +62,89 @@ func (c *sensorServiceClient) StreamReadings(ctx context.Context, opts ...grpc.C
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type SensorService_StreamReadingsClient = grpc.ClientStreamingClient[SensorReading, Ack]
 
+func (c *sensorServiceClient) StreamReadingBatches(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[ReadingBatch, BatchAck], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &SensorService_ServiceDesc.Streams[1], SensorService_StreamReadingBatches_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ReadingBatch, BatchAck]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SensorService_StreamReadingBatchesClient = grpc.BidiStreamingClient[ReadingBatch, BatchAck]
+
+func (c *sensorServiceClient) StreamTracks(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[SensorTrack, Ack], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &SensorService_ServiceDesc.Streams[2], SensorService_StreamTracks_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SensorTrack, Ack]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SensorService_StreamTracksClient = grpc.ClientStreamingClient[SensorTrack, Ack]
+
+func (c *sensorServiceClient) StreamBearings(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[BearingReading, Ack], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &SensorService_ServiceDesc.Streams[3], SensorService_StreamBearings_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[BearingReading, Ack]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SensorService_StreamBearingsClient = grpc.ClientStreamingClient[BearingReading, Ack]
+
+func (c *sensorServiceClient) StreamArrivals(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[ArrivalReading, Ack], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &SensorService_ServiceDesc.Streams[4], SensorService_StreamArrivals_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ArrivalReading, Ack]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SensorService_StreamArrivalsClient = grpc.ClientStreamingClient[ArrivalReading, Ack]
+
+func (c *sensorServiceClient) RegisterSensor(ctx context.Context, in *SensorRegistration, opts ...grpc.CallOption) (*RegisterResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RegisterResponse)
+	err := c.cc.Invoke(ctx, SensorService_RegisterSensor_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sensorServiceClient) Heartbeat(ctx context.Context, in *HeartbeatRequest, opts ...grpc.CallOption) (*HeartbeatResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HeartbeatResponse)
+	err := c.cc.Invoke(ctx, SensorService_Heartbeat_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // SensorServiceServer is the server API for SensorService service.
 // All implementations must embed UnimplementedSensorServiceServer
 // for forward compatibility.
 type SensorServiceServer interface {
 	StreamReadings(grpc.ClientStreamingServer[SensorReading, Ack]) error
+	StreamReadingBatches(grpc.BidiStreamingServer[ReadingBatch, BatchAck]) error
+	StreamTracks(grpc.ClientStreamingServer[SensorTrack, Ack]) error
+	StreamBearings(grpc.ClientStreamingServer[BearingReading, Ack]) error
+	StreamArrivals(grpc.ClientStreamingServer[ArrivalReading, Ack]) error
+	RegisterSensor(context.Context, *SensorRegistration) (*RegisterResponse, error)
+	Heartbeat(context.Context, *HeartbeatRequest) (*HeartbeatResponse, error)
 	mustEmbedUnimplementedSensorServiceServer()
 }
 
@@ -68,6 +158,24 @@ type UnimplementedSensorServiceServer struct{}
 func (UnimplementedSensorServiceServer) StreamReadings(grpc.ClientStreamingServer[SensorReading, Ack]) error {
 	return status.Error(codes.Unimplemented, "method StreamReadings not implemented")
 }
+func (UnimplementedSensorServiceServer) StreamReadingBatches(grpc.BidiStreamingServer[ReadingBatch, BatchAck]) error {
+	return status.Error(codes.Unimplemented, "method StreamReadingBatches not implemented")
+}
+func (UnimplementedSensorServiceServer) StreamTracks(grpc.ClientStreamingServer[SensorTrack, Ack]) error {
+	return status.Error(codes.Unimplemented, "method StreamTracks not implemented")
+}
+func (UnimplementedSensorServiceServer) StreamBearings(grpc.ClientStreamingServer[BearingReading, Ack]) error {
+	return status.Error(codes.Unimplemented, "method StreamBearings not implemented")
+}
+func (UnimplementedSensorServiceServer) StreamArrivals(grpc.ClientStreamingServer[ArrivalReading, Ack]) error {
+	return status.Error(codes.Unimplemented, "method StreamArrivals not implemented")
+}
+func (UnimplementedSensorServiceServer) RegisterSensor(context.Context, *SensorRegistration) (*RegisterResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method RegisterSensor not implemented")
+}
+func (UnimplementedSensorServiceServer) Heartbeat(context.Context, *HeartbeatRequest) (*HeartbeatResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Heartbeat not implemented")
+}
 func (UnimplementedSensorServiceServer) mustEmbedUnimplementedSensorServiceServer() {}
 func (UnimplementedSensorServiceServer) testEmbeddedByValue()                       {}
 
@@ -96,19 +204,113 @@ func _SensorService_StreamReadings_Handler(srv interface{}, stream grpc.ServerSt
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type SensorService_StreamReadingsServer = grpc.ClientStreamingServer[SensorReading, Ack]
 
+func _SensorService_StreamReadingBatches_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(SensorServiceServer).StreamReadingBatches(&grpc.GenericServerStream[ReadingBatch, BatchAck]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SensorService_StreamReadingBatchesServer = grpc.BidiStreamingServer[ReadingBatch, BatchAck]
+
+func _SensorService_StreamTracks_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(SensorServiceServer).StreamTracks(&grpc.GenericServerStream[SensorTrack, Ack]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SensorService_StreamTracksServer = grpc.ClientStreamingServer[SensorTrack, Ack]
+
+func _SensorService_StreamBearings_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(SensorServiceServer).StreamBearings(&grpc.GenericServerStream[BearingReading, Ack]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SensorService_StreamBearingsServer = grpc.ClientStreamingServer[BearingReading, Ack]
+
+func _SensorService_StreamArrivals_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(SensorServiceServer).StreamArrivals(&grpc.GenericServerStream[ArrivalReading, Ack]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SensorService_StreamArrivalsServer = grpc.ClientStreamingServer[ArrivalReading, Ack]
+
+func _SensorService_RegisterSensor_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SensorRegistration)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SensorServiceServer).RegisterSensor(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SensorService_RegisterSensor_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SensorServiceServer).RegisterSensor(ctx, req.(*SensorRegistration))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SensorService_Heartbeat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HeartbeatRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SensorServiceServer).Heartbeat(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SensorService_Heartbeat_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SensorServiceServer).Heartbeat(ctx, req.(*HeartbeatRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // SensorService_ServiceDesc is the grpc.ServiceDesc for SensorService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
 var SensorService_ServiceDesc = grpc.ServiceDesc{
 	ServiceName: "sensor.SensorService",
 	HandlerType: (*SensorServiceServer)(nil),
-	Methods:     []grpc.MethodDesc{},
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "RegisterSensor",
+			Handler:    _SensorService_RegisterSensor_Handler,
+		},
+		{
+			MethodName: "Heartbeat",
+			Handler:    _SensorService_Heartbeat_Handler,
+		},
+	},
 	Streams: []grpc.StreamDesc{
 		{
 			StreamName:    "StreamReadings",
 			Handler:       _SensorService_StreamReadings_Handler,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "StreamReadingBatches",
+			Handler:       _SensorService_StreamReadingBatches_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "StreamTracks",
+			Handler:       _SensorService_StreamTracks_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "StreamBearings",
+			Handler:       _SensorService_StreamBearings_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "StreamArrivals",
+			Handler:       _SensorService_StreamArrivals_Handler,
+			ClientStreams: true,
+		},
 	},
 	Metadata: "proto/sensor.proto",
 }
@@ -30,6 +30,7 @@ type Threat struct {
 	Vx            float64                `protobuf:"fixed64,4,opt,name=vx,proto3" json:"vx,omitempty"`
 	Vy            float64                `protobuf:"fixed64,5,opt,name=vy,proto3" json:"vy,omitempty"`
 	Level         int32                  `protobuf:"varint,6,opt,name=level,proto3" json:"level,omitempty"`
+	Motion        *Motion                `protobuf:"bytes,7,opt,name=motion,proto3" json:"motion,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -106,17 +107,231 @@ func (x *Threat) GetLevel() int32 {
 	return 0
 }
 
-type WorldState struct {
+func (x *Threat) GetMotion() *Motion {
+	if x != nil {
+		return x.Motion
+	}
+	return nil
+}
+
+type Point struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	X             float64                `protobuf:"fixed64,1,opt,name=x,proto3" json:"x,omitempty"`
+	Y             float64                `protobuf:"fixed64,2,opt,name=y,proto3" json:"y,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Point) Reset() {
+	*x = Point{}
+	mi := &file_world_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Point) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Point) ProtoMessage() {}
+
+func (x *Point) ProtoReflect() protoreflect.Message {
+	mi := &file_world_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Point.ProtoReflect.Descriptor instead.
+func (*Point) Descriptor() ([]byte, []int) {
+	return file_world_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Point) GetX() float64 {
+	if x != nil {
+		return x.X
+	}
+	return 0
+}
+
+func (x *Point) GetY() float64 {
+	if x != nil {
+		return x.Y
+	}
+	return 0
+}
+
+// Motion is how a threat is moving, so evaluation can correlate tracker
+// errors with manoeuvres. Params hold the model's settings and current
+// state, e.g. a Singer model's acceleration.
+type Motion struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Threats       []*Threat              `protobuf:"bytes,1,rep,name=threats,proto3" json:"threats,omitempty"`
-	Tick          int32                  `protobuf:"varint,2,opt,name=tick,proto3" json:"tick,omitempty"`
+	Model         string                 `protobuf:"bytes,1,opt,name=model,proto3" json:"model,omitempty"`
+	Params        map[string]float64     `protobuf:"bytes,2,rep,name=params,proto3" json:"params,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"fixed64,2,opt,name=value"`
+	Waypoints     []*Point               `protobuf:"bytes,3,rep,name=waypoints,proto3" json:"waypoints,omitempty"` // Waypoint model only
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Motion) Reset() {
+	*x = Motion{}
+	mi := &file_world_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Motion) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Motion) ProtoMessage() {}
+
+func (x *Motion) ProtoReflect() protoreflect.Message {
+	mi := &file_world_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Motion.ProtoReflect.Descriptor instead.
+func (*Motion) Descriptor() ([]byte, []int) {
+	return file_world_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Motion) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+func (x *Motion) GetParams() map[string]float64 {
+	if x != nil {
+		return x.Params
+	}
+	return nil
+}
+
+func (x *Motion) GetWaypoints() []*Point {
+	if x != nil {
+		return x.Waypoints
+	}
+	return nil
+}
+
+// Obstacle is a static line-of-sight blocker: a circle when vertices is
+// empty, otherwise a polygon
+type Obstacle struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	X             float64                `protobuf:"fixed64,2,opt,name=x,proto3" json:"x,omitempty"`
+	Y             float64                `protobuf:"fixed64,3,opt,name=y,proto3" json:"y,omitempty"`
+	Radius        float64                `protobuf:"fixed64,4,opt,name=radius,proto3" json:"radius,omitempty"`
+	Vertices      []*Point               `protobuf:"bytes,5,rep,name=vertices,proto3" json:"vertices,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Obstacle) Reset() {
+	*x = Obstacle{}
+	mi := &file_world_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Obstacle) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Obstacle) ProtoMessage() {}
+
+func (x *Obstacle) ProtoReflect() protoreflect.Message {
+	mi := &file_world_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Obstacle.ProtoReflect.Descriptor instead.
+func (*Obstacle) Descriptor() ([]byte, []int) {
+	return file_world_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Obstacle) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Obstacle) GetX() float64 {
+	if x != nil {
+		return x.X
+	}
+	return 0
+}
+
+func (x *Obstacle) GetY() float64 {
+	if x != nil {
+		return x.Y
+	}
+	return 0
+}
+
+func (x *Obstacle) GetRadius() float64 {
+	if x != nil {
+		return x.Radius
+	}
+	return 0
+}
+
+func (x *Obstacle) GetVertices() []*Point {
+	if x != nil {
+		return x.Vertices
+	}
+	return nil
+}
+
+type WorldState struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Threats   []*Threat              `protobuf:"bytes,1,rep,name=threats,proto3" json:"threats,omitempty"`
+	Tick      int32                  `protobuf:"varint,2,opt,name=tick,proto3" json:"tick,omitempty"`
+	Width     float64                `protobuf:"fixed64,3,opt,name=width,proto3" json:"width,omitempty"`
+	Height    float64                `protobuf:"fixed64,4,opt,name=height,proto3" json:"height,omitempty"`
+	Obstacles []*Obstacle            `protobuf:"bytes,5,rep,name=obstacles,proto3" json:"obstacles,omitempty"`
+	// What happens at the edges: "torus" (wrap round, also when empty),
+	// "reflect" or "absorb". Bounded worlds use plain Euclidean geometry.
+	Boundary string `protobuf:"bytes,6,opt,name=boundary,proto3" json:"boundary,omitempty"`
+	// Bumped whenever the world is replaced, by a restart or a snapshot
+	// load. The new world doesn't follow on from the old one, so anything
+	// kept about its threats should be dropped.
+	Epoch int32 `protobuf:"varint,7,opt,name=epoch,proto3" json:"epoch,omitempty"`
+	// When the world reached this tick, in Unix nanoseconds on the world
+	// server's clock. Sources emit at this moment, so acoustic arrival
+	// times are reckoned from it.
+	Timestamp     int64 `protobuf:"varint,8,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *WorldState) Reset() {
 	*x = WorldState{}
-	mi := &file_world_proto_msgTypes[1]
+	mi := &file_world_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -128,7 +343,7 @@ func (x *WorldState) String() string {
 func (*WorldState) ProtoMessage() {}
 
 func (x *WorldState) ProtoReflect() protoreflect.Message {
-	mi := &file_world_proto_msgTypes[1]
+	mi := &file_world_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -141,7 +356,7 @@ func (x *WorldState) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use WorldState.ProtoReflect.Descriptor instead.
 func (*WorldState) Descriptor() ([]byte, []int) {
-	return file_world_proto_rawDescGZIP(), []int{1}
+	return file_world_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *WorldState) GetThreats() []*Threat {
@@ -158,6 +373,48 @@ func (x *WorldState) GetTick() int32 {
 	return 0
 }
 
+func (x *WorldState) GetWidth() float64 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *WorldState) GetHeight() float64 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *WorldState) GetObstacles() []*Obstacle {
+	if x != nil {
+		return x.Obstacles
+	}
+	return nil
+}
+
+func (x *WorldState) GetBoundary() string {
+	if x != nil {
+		return x.Boundary
+	}
+	return ""
+}
+
+func (x *WorldState) GetEpoch() int32 {
+	if x != nil {
+		return x.Epoch
+	}
+	return 0
+}
+
+func (x *WorldState) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
 type SubscribeRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
@@ -166,7 +423,7 @@ type SubscribeRequest struct {
 
 func (x *SubscribeRequest) Reset() {
 	*x = SubscribeRequest{}
-	mi := &file_world_proto_msgTypes[2]
+	mi := &file_world_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -178,7 +435,7 @@ func (x *SubscribeRequest) String() string {
 func (*SubscribeRequest) ProtoMessage() {}
 
 func (x *SubscribeRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_world_proto_msgTypes[2]
+	mi := &file_world_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -191,25 +448,48 @@ func (x *SubscribeRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SubscribeRequest.ProtoReflect.Descriptor instead.
 func (*SubscribeRequest) Descriptor() ([]byte, []int) {
-	return file_world_proto_rawDescGZIP(), []int{2}
+	return file_world_proto_rawDescGZIP(), []int{5}
 }
 
 var File_world_proto protoreflect.FileDescriptor
 
 const file_world_proto_rawDesc = "" +
 	"\n" +
-	"\vworld.proto\x12\x05world\"j\n" +
+	"\vworld.proto\x12\x05world\"\x91\x01\n" +
 	"\x06Threat\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x05R\x02id\x12\f\n" +
 	"\x01x\x18\x02 \x01(\x01R\x01x\x12\f\n" +
 	"\x01y\x18\x03 \x01(\x01R\x01y\x12\x0e\n" +
 	"\x02vx\x18\x04 \x01(\x01R\x02vx\x12\x0e\n" +
 	"\x02vy\x18\x05 \x01(\x01R\x02vy\x12\x14\n" +
-	"\x05level\x18\x06 \x01(\x05R\x05level\"I\n" +
+	"\x05level\x18\x06 \x01(\x05R\x05level\x12%\n" +
+	"\x06motion\x18\a \x01(\v2\r.world.MotionR\x06motion\"#\n" +
+	"\x05Point\x12\f\n" +
+	"\x01x\x18\x01 \x01(\x01R\x01x\x12\f\n" +
+	"\x01y\x18\x02 \x01(\x01R\x01y\"\xb8\x01\n" +
+	"\x06Motion\x12\x14\n" +
+	"\x05model\x18\x01 \x01(\tR\x05model\x121\n" +
+	"\x06params\x18\x02 \x03(\v2\x19.world.Motion.ParamsEntryR\x06params\x12*\n" +
+	"\twaypoints\x18\x03 \x03(\v2\f.world.PointR\twaypoints\x1a9\n" +
+	"\vParamsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x01R\x05value:\x028\x01\"x\n" +
+	"\bObstacle\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\x12\f\n" +
+	"\x01x\x18\x02 \x01(\x01R\x01x\x12\f\n" +
+	"\x01y\x18\x03 \x01(\x01R\x01y\x12\x16\n" +
+	"\x06radius\x18\x04 \x01(\x01R\x06radius\x12(\n" +
+	"\bvertices\x18\x05 \x03(\v2\f.world.PointR\bvertices\"\xf6\x01\n" +
 	"\n" +
 	"WorldState\x12'\n" +
 	"\athreats\x18\x01 \x03(\v2\r.world.ThreatR\athreats\x12\x12\n" +
-	"\x04tick\x18\x02 \x01(\x05R\x04tick\"\x12\n" +
+	"\x04tick\x18\x02 \x01(\x05R\x04tick\x12\x14\n" +
+	"\x05width\x18\x03 \x01(\x01R\x05width\x12\x16\n" +
+	"\x06height\x18\x04 \x01(\x01R\x06height\x12-\n" +
+	"\tobstacles\x18\x05 \x03(\v2\x0f.world.ObstacleR\tobstacles\x12\x1a\n" +
+	"\bboundary\x18\x06 \x01(\tR\bboundary\x12\x14\n" +
+	"\x05epoch\x18\a \x01(\x05R\x05epoch\x12\x1c\n" +
+	"\ttimestamp\x18\b \x01(\x03R\ttimestamp\"\x12\n" +
 	"\x10SubscribeRequest2I\n" +
 	"\fWorldService\x129\n" +
 	"\tSubscribe\x12\x17.world.SubscribeRequest\x1a\x11.world.WorldState0\x01B4Z2distributed-sensor-fusion/shared/generated/worldpbb\x06proto3"
@@ -226,21 +506,30 @@ func file_world_proto_rawDescGZIP() []byte {
 	return file_world_proto_rawDescData
 }
 
-var file_world_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_world_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
 var file_world_proto_goTypes = []any{
 	(*Threat)(nil),           // 0: world.Threat
-	(*WorldState)(nil),       // 1: world.WorldState
-	(*SubscribeRequest)(nil), // 2: world.SubscribeRequest
+	(*Point)(nil),            // 1: world.Point
+	(*Motion)(nil),           // 2: world.Motion
+	(*Obstacle)(nil),         // 3: world.Obstacle
+	(*WorldState)(nil),       // 4: world.WorldState
+	(*SubscribeRequest)(nil), // 5: world.SubscribeRequest
+	nil,                      // 6: world.Motion.ParamsEntry
 }
 var file_world_proto_depIdxs = []int32{
-	0, // 0: world.WorldState.threats:type_name -> world.Threat
-	2, // 1: world.WorldService.Subscribe:input_type -> world.SubscribeRequest
-	1, // 2: world.WorldService.Subscribe:output_type -> world.WorldState
-	2, // [2:3] is the sub-list for method output_type
-	1, // [1:2] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	2, // 0: world.Threat.motion:type_name -> world.Motion
+	6, // 1: world.Motion.params:type_name -> world.Motion.ParamsEntry
+	1, // 2: world.Motion.waypoints:type_name -> world.Point
+	1, // 3: world.Obstacle.vertices:type_name -> world.Point
+	0, // 4: world.WorldState.threats:type_name -> world.Threat
+	3, // 5: world.WorldState.obstacles:type_name -> world.Obstacle
+	5, // 6: world.WorldService.Subscribe:input_type -> world.SubscribeRequest
+	4, // 7: world.WorldService.Subscribe:output_type -> world.WorldState
+	7, // [7:8] is the sub-list for method output_type
+	6, // [6:7] is the sub-list for method input_type
+	6, // [6:6] is the sub-list for extension type_name
+	6, // [6:6] is the sub-list for extension extendee
+	0, // [0:6] is the sub-list for field type_name
 }
 
 func init() { file_world_proto_init() }
@@ -254,7 +543,7 @@ func file_world_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_world_proto_rawDesc), len(file_world_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   3,
+			NumMessages:   7,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -0,0 +1,185 @@
+package world
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// apiError is the body of every failed control API request
+type apiError struct {
+	Status int    `json:"status"`
+	Error  string `json:"error"`
+	Field  string `json:"field,omitempty"` // The offending input, where there is one
+}
+
+func writeError(w http.ResponseWriter, status int, field, format string, args ...interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(apiError{
+		Status: status,
+		Error:  fmt.Sprintf(format, args...),
+		Field:  field,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// decodeBody reads a JSON request body, refusing unknown fields. It writes
+// the error response itself and reports whether the caller can go on.
+func decodeBody(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	err := dec.Decode(v)
+	if err == nil {
+		return true
+	}
+
+	var typ *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &typ):
+		writeError(w, http.StatusBadRequest, fieldPath(typ.Field), "expected %s", typ.Type)
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		writeError(w, http.StatusBadRequest, field, "unknown field")
+	default:
+		writeError(w, http.StatusBadRequest, "", "invalid JSON body: %v", err)
+	}
+	return false
+}
+
+// threatView is a threat as the control API shows it
+type threatView struct {
+	ID           int                `json:"id"`
+	X            float64            `json:"x"`
+	Y            float64            `json:"y"`
+	VX           float64            `json:"vx"`
+	VY           float64            `json:"vy"`
+	Level        int                `json:"level"`
+	Motion       string             `json:"motion"`
+	MotionParams map[string]float64 `json:"motion_params,omitempty"`
+	DespawnTick  int                `json:"despawn_tick,omitempty"`
+}
+
+func viewThreat(t Threat) threatView {
+	return threatView{
+		ID:           t.ID,
+		X:            t.X,
+		Y:            t.Y,
+		VX:           t.VX,
+		VY:           t.VY,
+		Level:        t.Level,
+		Motion:       t.Motion().Name(),
+		MotionParams: t.Motion().Params(),
+		DespawnTick:  t.despawnTick,
+	}
+}
+
+// handleThreats serves /threats: GET lists the live threats, POST adds one
+func (s *WorldServer) handleThreats(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		s.mu.RLock()
+		views := make([]threatView, len(s.world.Threats))
+		for i, t := range s.world.Threats {
+			views[i] = viewThreat(t)
+		}
+		s.mu.RUnlock()
+		writeJSON(w, http.StatusOK, views)
+
+	case http.MethodPost:
+		var nt NewThreat
+		if !decodeBody(w, r, &nt) {
+			return
+		}
+		s.mu.Lock()
+		t, err := s.world.AddThreat(nt)
+		s.mu.Unlock()
+		if err != nil {
+			writeEditError(w, err)
+			return
+		}
+		log.Printf("Threat %d added at (%.1f, %.1f)", t.ID, t.X, t.Y)
+		s.broadcast(false)
+		writeJSON(w, http.StatusCreated, viewThreat(t))
+
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "", "use GET to list threats or POST to add one")
+	}
+}
+
+// handleThreat serves /threats/{id}: GET shows the threat, PATCH changes
+// its position, velocity or level and DELETE removes it
+func (s *WorldServer) handleThreat(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "id", "%q is not a threat ID", r.PathValue("id"))
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		s.mu.RLock()
+		t, ok := s.world.Threat(id)
+		s.mu.RUnlock()
+		if !ok {
+			writeEditError(w, ErrNoSuchThreat)
+			return
+		}
+		writeJSON(w, http.StatusOK, viewThreat(t))
+
+	case http.MethodPatch:
+		var p ThreatPatch
+		if !decodeBody(w, r, &p) {
+			return
+		}
+		s.mu.Lock()
+		t, err := s.world.PatchThreat(id, p)
+		s.mu.Unlock()
+		if err != nil {
+			writeEditError(w, err)
+			return
+		}
+		log.Printf("Threat %d changed: (%.1f, %.1f) v=(%.2f, %.2f) level=%d", t.ID, t.X, t.Y, t.VX, t.VY, t.Level)
+		s.broadcast(false)
+		writeJSON(w, http.StatusOK, viewThreat(t))
+
+	case http.MethodDelete:
+		s.mu.Lock()
+		err := s.world.RemoveThreat(id)
+		s.mu.Unlock()
+		if err != nil {
+			writeEditError(w, err)
+			return
+		}
+		log.Printf("Threat %d removed", id)
+		s.broadcast(false)
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "", "use GET, PATCH or DELETE on a threat")
+	}
+}
+
+// writeEditError maps a world edit's error onto a response
+func writeEditError(w http.ResponseWriter, err error) {
+	var fe *FieldError
+	switch {
+	case errors.As(err, &fe):
+		writeError(w, http.StatusUnprocessableEntity, fe.Field, "%s", fe.Reason)
+	case errors.Is(err, ErrNoSuchThreat):
+		writeError(w, http.StatusNotFound, "id", "%v", err)
+	case errors.Is(err, ErrIDUsed):
+		writeError(w, http.StatusConflict, "id", "%v", err)
+	default:
+		writeError(w, http.StatusBadRequest, "", "%v", err)
+	}
+}
@@ -0,0 +1,193 @@
+package world
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+var (
+	ErrNoSuchThreat = errors.New("no such threat")
+	ErrIDUsed       = errors.New("already used in this run")
+)
+
+// FieldError rejects one input value, naming the field it came from
+type FieldError struct {
+	Field  string
+	Reason string
+}
+
+func (e *FieldError) Error() string {
+	return e.Field + ": " + e.Reason
+}
+
+// NewThreat is a threat to add to the running world
+type NewThreat struct {
+	ID          int        `json:"id"` // 0 or missing for the next unused ID; at most MaxThreatID
+	X           float64    `json:"x"`
+	Y           float64    `json:"y"`
+	VX          float64    `json:"vx"` // Units per tick
+	VY          float64    `json:"vy"`
+	Level       int        `json:"level"`
+	Motion      MotionSpec `json:"motion"`
+	DespawnTick int        `json:"despawn_tick"` // 0 for never
+}
+
+// ThreatPatch changes the fields of a threat that are set. A new velocity
+// is overridden by motion models that steer by themselves, such as
+// waypoint and stop-and-go.
+type ThreatPatch struct {
+	X     *float64 `json:"x"`
+	Y     *float64 `json:"y"`
+	VX    *float64 `json:"vx"`
+	VY    *float64 `json:"vy"`
+	Level *int     `json:"level"`
+}
+
+// AddThreat puts a new threat into the world. An ID that has been used
+// before in this run is refused, so downstream never sees one reused.
+func (w *World) AddThreat(nt NewThreat) (Threat, error) {
+	if nt.ID < 0 {
+		return Threat{}, &FieldError{"id", "must be positive"}
+	}
+	if nt.ID > MaxThreatID {
+		return Threat{}, &FieldError{"id", fmt.Sprintf("%d is above %d", nt.ID, MaxThreatID)}
+	}
+	if nt.ID != 0 && nt.ID < w.nextID {
+		return Threat{}, fmt.Errorf("id %d: %w", nt.ID, ErrIDUsed)
+	}
+	if nt.ID == 0 && w.nextID > MaxThreatID {
+		return Threat{}, &FieldError{"id", "no unused IDs left"}
+	}
+	if err := w.checkPosition(nt.X, nt.Y); err != nil {
+		return Threat{}, err
+	}
+	if err := w.checkVelocity(nt.VX, nt.VY); err != nil {
+		return Threat{}, err
+	}
+	if err := checkLevel(nt.Level); err != nil {
+		return Threat{}, err
+	}
+	if err := nt.Motion.validate(); err != nil {
+		return Threat{}, &FieldError{"motion", err.Error()}
+	}
+	if nt.DespawnTick != 0 && nt.DespawnTick <= w.Tick {
+		return Threat{}, &FieldError{"despawn_tick", fmt.Sprintf("%d is not after the current tick %d", nt.DespawnTick, w.Tick)}
+	}
+
+	if nt.ID == 0 {
+		nt.ID = w.nextID
+	}
+	w.nextID = nt.ID + 1
+	t := Threat{
+		X:           nt.X,
+		Y:           nt.Y,
+		VX:          nt.VX,
+		VY:          nt.VY,
+		ID:          nt.ID,
+		Level:       nt.Level,
+		motion:      nt.Motion.newModel(),
+		despawnTick: nt.DespawnTick,
+	}
+	w.Threats = append(w.Threats, t)
+	return t, nil
+}
+
+// PatchThreat changes a live threat's state. Nothing changes unless the
+// whole patch is valid.
+func (w *World) PatchThreat(id int, p ThreatPatch) (Threat, error) {
+	t := w.threat(id)
+	if t == nil {
+		return Threat{}, ErrNoSuchThreat
+	}
+
+	x, y, vx, vy, level := t.X, t.Y, t.VX, t.VY, t.Level
+	if p.X != nil {
+		x = *p.X
+	}
+	if p.Y != nil {
+		y = *p.Y
+	}
+	if p.VX != nil {
+		vx = *p.VX
+	}
+	if p.VY != nil {
+		vy = *p.VY
+	}
+	if p.Level != nil {
+		level = *p.Level
+	}
+	if err := w.checkPosition(x, y); err != nil {
+		return Threat{}, err
+	}
+	if err := w.checkVelocity(vx, vy); err != nil {
+		return Threat{}, err
+	}
+	if err := checkLevel(level); err != nil {
+		return Threat{}, err
+	}
+
+	t.X, t.Y, t.VX, t.VY, t.Level = x, y, vx, vy, level
+	if p.Level != nil {
+		t.levelChanges = nil // A scripted change would undo this one
+	}
+	return *t, nil
+}
+
+// RemoveThreat takes a threat out of the world. Its ID stays used.
+func (w *World) RemoveThreat(id int) error {
+	for i := range w.Threats {
+		if w.Threats[i].ID == id {
+			w.Threats = append(w.Threats[:i], w.Threats[i+1:]...)
+			return nil
+		}
+	}
+	return ErrNoSuchThreat
+}
+
+// Threat returns a live threat by ID
+func (w *World) Threat(id int) (Threat, bool) {
+	if t := w.threat(id); t != nil {
+		return *t, true
+	}
+	return Threat{}, false
+}
+
+func (w *World) threat(id int) *Threat {
+	for i := range w.Threats {
+		if w.Threats[i].ID == id {
+			return &w.Threats[i]
+		}
+	}
+	return nil
+}
+
+func (w *World) checkPosition(x, y float64) error {
+	if !onAxis(x, w.Width, w.Bounded()) {
+		return &FieldError{"x", fmt.Sprintf("%g is outside 0-%g", x, w.Width)}
+	}
+	if !onAxis(y, w.Height, w.Bounded()) {
+		return &FieldError{"y", fmt.Sprintf("%g is outside 0-%g", y, w.Height)}
+	}
+	return nil
+}
+
+// checkVelocity refuses speeds that would carry a threat across the whole
+// world in a single tick, or that aren't numbers at all
+func (w *World) checkVelocity(vx, vy float64) error {
+	limit := math.Max(w.Width, w.Height)
+	if math.IsNaN(vx) || math.Abs(vx) >= limit {
+		return &FieldError{"vx", fmt.Sprintf("%g is not below %g in size", vx, limit)}
+	}
+	if math.IsNaN(vy) || math.Abs(vy) >= limit {
+		return &FieldError{"vy", fmt.Sprintf("%g is not below %g in size", vy, limit)}
+	}
+	return nil
+}
+
+func checkLevel(level int) error {
+	if level < 1 || level > 10 {
+		return &FieldError{"level", fmt.Sprintf("%d is out of range 1-10", level)}
+	}
+	return nil
+}
@@ -0,0 +1,295 @@
+package world
+
+import (
+	"fmt"
+	"math"
+
+	pb "distributed-sensor-fusion/shared/generated/worldpb"
+)
+
+// Motion models
+const (
+	MotionConstantVelocity = "constant_velocity"
+	MotionCoordinatedTurn  = "coordinated_turn"
+	MotionSinger           = "singer"
+	MotionWaypoint         = "waypoint"
+	MotionStopAndGo        = "stop_and_go"
+)
+
+// MotionModel decides how a threat moves. Each threat has its own model
+// instance, which may keep state between ticks.
+type MotionModel interface {
+	Name() string
+	// Params are the model's settings and current state, for the truth stream
+	Params() map[string]float64
+	// Steer sets the threat's velocity for the coming tick; the world
+	// then moves it by that velocity
+	Steer(t *Threat, w *World)
+}
+
+// savedMotion is a motion model that snapshots can save and restore: the
+// spec it was built from, and the state it has built up since
+type savedMotion interface {
+	spec() MotionSpec
+	state() map[string]float64
+	restore(state map[string]float64)
+}
+
+// MotionSpec picks a threat's motion model and its settings. Only the
+// fields for the chosen model are used.
+type MotionSpec struct {
+	Model string `json:"model"` // Default constant_velocity
+
+	TurnRate float64 `json:"turn_rate,omitempty"` // Coordinated turn: radians per tick, positive counter-clockwise
+
+	Tau      float64 `json:"tau,omitempty"`       // Singer: acceleration correlation time, ticks
+	Sigma    float64 `json:"sigma,omitempty"`     // Singer: acceleration standard deviation, units per tick²
+	MaxSpeed float64 `json:"max_speed,omitempty"` // Singer: speed limit in units per tick, 0 for none
+
+	Waypoints []Point `json:"waypoints,omitempty"` // Waypoint: points to visit in order
+	Speed     float64 `json:"speed,omitempty"`     // Waypoint: units per tick
+	Loop      bool    `json:"loop,omitempty"`      // Waypoint: start over after the last point instead of stopping
+
+	MoveTicks int `json:"move_ticks,omitempty"` // Stop-and-go: ticks spent moving
+	StopTicks int `json:"stop_ticks,omitempty"` // Stop-and-go: ticks spent stopped
+}
+
+func (m *MotionSpec) validate() error {
+	switch m.Model {
+	case "":
+		m.Model = MotionConstantVelocity
+	case MotionConstantVelocity, MotionCoordinatedTurn:
+	case MotionSinger:
+		if m.Tau <= 0 || m.Sigma < 0 {
+			return fmt.Errorf("singer needs a positive tau and a non-negative sigma")
+		}
+		if m.MaxSpeed < 0 {
+			return fmt.Errorf("max_speed must not be negative")
+		}
+	case MotionWaypoint:
+		if len(m.Waypoints) == 0 {
+			return fmt.Errorf("waypoint needs at least one waypoint")
+		}
+		if m.Speed <= 0 {
+			return fmt.Errorf("waypoint needs a positive speed")
+		}
+	case MotionStopAndGo:
+		if m.MoveTicks <= 0 || m.StopTicks <= 0 {
+			return fmt.Errorf("stop_and_go needs positive move_ticks and stop_ticks")
+		}
+	default:
+		return fmt.Errorf("unknown model %q", m.Model)
+	}
+	return nil
+}
+
+// newModel builds a fresh instance of the model the spec describes
+func (m MotionSpec) newModel() MotionModel {
+	switch m.Model {
+	case MotionCoordinatedTurn:
+		return &coordinatedTurn{turnRate: m.TurnRate}
+	case MotionSinger:
+		return &singer{tau: m.Tau, sigma: m.Sigma, maxSpeed: m.MaxSpeed}
+	case MotionWaypoint:
+		return &waypoint{points: m.Waypoints, speed: m.Speed, loop: m.Loop}
+	case MotionStopAndGo:
+		return &stopAndGo{moveTicks: m.MoveTicks, stopTicks: m.StopTicks}
+	}
+	return constantVelocity{}
+}
+
+// motionToProto describes a threat's motion for the truth stream
+func motionToProto(m MotionModel) *pb.Motion {
+	motion := &pb.Motion{Model: m.Name(), Params: m.Params()}
+	if wp, ok := m.(*waypoint); ok {
+		for _, p := range wp.points {
+			motion.Waypoints = append(motion.Waypoints, &pb.Point{X: p.X, Y: p.Y})
+		}
+	}
+	return motion
+}
+
+// constantVelocity keeps going in a straight line
+type constantVelocity struct{}
+
+func (constantVelocity) Name() string               { return MotionConstantVelocity }
+func (constantVelocity) Params() map[string]float64 { return nil }
+func (constantVelocity) Steer(*Threat, *World)      {}
+func (constantVelocity) spec() MotionSpec           { return MotionSpec{Model: MotionConstantVelocity} }
+func (constantVelocity) state() map[string]float64  { return nil }
+func (constantVelocity) restore(map[string]float64) {}
+
+// coordinatedTurn turns at a constant rate without changing speed
+type coordinatedTurn struct {
+	turnRate float64
+}
+
+func (m *coordinatedTurn) Name() string { return MotionCoordinatedTurn }
+
+func (m *coordinatedTurn) Params() map[string]float64 {
+	return map[string]float64{"turn_rate": m.turnRate}
+}
+
+func (m *coordinatedTurn) Steer(t *Threat, w *World) {
+	sin, cos := math.Sincos(m.turnRate)
+	t.VX, t.VY = t.VX*cos-t.VY*sin, t.VX*sin+t.VY*cos
+}
+
+func (m *coordinatedTurn) spec() MotionSpec {
+	return MotionSpec{Model: MotionCoordinatedTurn, TurnRate: m.turnRate}
+}
+
+func (m *coordinatedTurn) state() map[string]float64  { return nil }
+func (m *coordinatedTurn) restore(map[string]float64) {}
+
+// singer is the Singer random acceleration model: acceleration is a
+// first-order Gauss-Markov process with correlation time tau, so
+// manoeuvres come and go rather than jittering every tick
+type singer struct {
+	tau, sigma, maxSpeed float64
+	ax, ay               float64
+}
+
+func (m *singer) Name() string { return MotionSinger }
+
+func (m *singer) Params() map[string]float64 {
+	return map[string]float64{
+		"tau":       m.tau,
+		"sigma":     m.sigma,
+		"max_speed": m.maxSpeed,
+		"ax":        m.ax,
+		"ay":        m.ay,
+	}
+}
+
+func (m *singer) Steer(t *Threat, w *World) {
+	rho := math.Exp(-1 / m.tau)
+	drive := m.sigma * math.Sqrt(1-rho*rho)
+	m.ax = rho*m.ax + drive*w.rng.NormFloat64()
+	m.ay = rho*m.ay + drive*w.rng.NormFloat64()
+
+	t.VX += m.ax
+	t.VY += m.ay
+	if speed := math.Hypot(t.VX, t.VY); m.maxSpeed > 0 && speed > m.maxSpeed {
+		t.VX *= m.maxSpeed / speed
+		t.VY *= m.maxSpeed / speed
+	}
+}
+
+func (m *singer) spec() MotionSpec {
+	return MotionSpec{Model: MotionSinger, Tau: m.tau, Sigma: m.sigma, MaxSpeed: m.maxSpeed}
+}
+
+func (m *singer) state() map[string]float64 {
+	return map[string]float64{"ax": m.ax, "ay": m.ay}
+}
+
+func (m *singer) restore(state map[string]float64) {
+	m.ax, m.ay = state["ax"], state["ay"]
+}
+
+// waypoint heads straight for each point in turn at a fixed speed, the
+// short way round on a torus
+type waypoint struct {
+	points []Point
+	speed  float64
+	loop   bool
+	next   int
+}
+
+func (m *waypoint) Name() string { return MotionWaypoint }
+
+func (m *waypoint) Params() map[string]float64 {
+	loop := 0.0
+	if m.loop {
+		loop = 1
+	}
+	return map[string]float64{"speed": m.speed, "loop": loop, "next": float64(m.next)}
+}
+
+func (m *waypoint) Steer(t *Threat, w *World) {
+	if m.next >= len(m.points) {
+		t.VX, t.VY = 0, 0 // Arrived
+		return
+	}
+
+	p := m.points[m.next]
+	dx, dy := w.delta(t.X, p.X, w.Width), w.delta(t.Y, p.Y, w.Height)
+	dist := math.Hypot(dx, dy)
+	if dist <= m.speed {
+		// Land on the point this tick and aim for the next one after
+		t.VX, t.VY = dx, dy
+		m.next++
+		if m.loop && m.next == len(m.points) {
+			m.next = 0
+		}
+		return
+	}
+	t.VX, t.VY = dx/dist*m.speed, dy/dist*m.speed
+}
+
+func (m *waypoint) spec() MotionSpec {
+	return MotionSpec{Model: MotionWaypoint, Waypoints: m.points, Speed: m.speed, Loop: m.loop}
+}
+
+func (m *waypoint) state() map[string]float64 {
+	return map[string]float64{"next": float64(m.next)}
+}
+
+func (m *waypoint) restore(state map[string]float64) {
+	m.next = min(max(int(state["next"]), 0), len(m.points))
+}
+
+// stopAndGo alternates between moving at the threat's cruise velocity and
+// holding still
+type stopAndGo struct {
+	moveTicks, stopTicks int
+	tick                 int     // Ticks into the current cycle
+	stopped              bool    // Held still this tick
+	vx, vy               float64 // Cruise velocity, kept while stopped
+}
+
+func (m *stopAndGo) Name() string { return MotionStopAndGo }
+
+func (m *stopAndGo) Params() map[string]float64 {
+	stopped := 0.0
+	if m.stopped {
+		stopped = 1
+	}
+	return map[string]float64{
+		"move_ticks": float64(m.moveTicks),
+		"stop_ticks": float64(m.stopTicks),
+		"stopped":    stopped,
+	}
+}
+
+func (m *stopAndGo) Steer(t *Threat, w *World) {
+	if m.tick == 0 && (t.VX != 0 || t.VY != 0) {
+		m.vx, m.vy = t.VX, t.VY
+	}
+	m.stopped = m.tick >= m.moveTicks
+	if m.stopped {
+		t.VX, t.VY = 0, 0
+	} else {
+		t.VX, t.VY = m.vx, m.vy
+	}
+	m.tick = (m.tick + 1) % (m.moveTicks + m.stopTicks)
+}
+
+func (m *stopAndGo) spec() MotionSpec {
+	return MotionSpec{Model: MotionStopAndGo, MoveTicks: m.moveTicks, StopTicks: m.stopTicks}
+}
+
+func (m *stopAndGo) state() map[string]float64 {
+	stopped := 0.0
+	if m.stopped {
+		stopped = 1
+	}
+	return map[string]float64{"tick": float64(m.tick), "stopped": stopped, "vx": m.vx, "vy": m.vy}
+}
+
+func (m *stopAndGo) restore(state map[string]float64) {
+	m.tick = max(int(state["tick"]), 0) % (m.moveTicks + m.stopTicks)
+	m.stopped = state["stopped"] != 0
+	m.vx, m.vy = state["vx"], state["vy"]
+}
@@ -0,0 +1,74 @@
+package world
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	pb "distributed-sensor-fusion/shared/generated/worldpb"
+)
+
+type Point struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// Obstacle blocks line of sight. It is a circle around (X, Y) unless
+// Vertices are given, in which case it is that polygon.
+type Obstacle struct {
+	ID       int     `json:"id"`
+	X        float64 `json:"x"`
+	Y        float64 `json:"y"`
+	Radius   float64 `json:"radius"`
+	Vertices []Point `json:"vertices,omitempty"`
+}
+
+// LoadObstacles reads a JSON array of obstacles. Missing IDs are numbered
+// by position in the file.
+func LoadObstacles(path string) ([]Obstacle, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var obstacles []Obstacle
+	if err := json.Unmarshal(data, &obstacles); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	for i := range obstacles {
+		if obstacles[i].ID == 0 {
+			obstacles[i].ID = i + 1
+		}
+		if err := obstacles[i].validate(); err != nil {
+			return nil, fmt.Errorf("%s: obstacles[%d]: %w", path, i, err)
+		}
+	}
+	return obstacles, nil
+}
+
+func (o Obstacle) validate() error {
+	if len(o.Vertices) == 0 {
+		if o.Radius <= 0 {
+			return fmt.Errorf("circle needs a positive radius")
+		}
+		return nil
+	}
+	if len(o.Vertices) < 3 {
+		return fmt.Errorf("polygon needs at least 3 vertices, got %d", len(o.Vertices))
+	}
+	return nil
+}
+
+func (o Obstacle) toProto() *pb.Obstacle {
+	vertices := make([]*pb.Point, len(o.Vertices))
+	for i, v := range o.Vertices {
+		vertices[i] = &pb.Point{X: v.X, Y: v.Y}
+	}
+	return &pb.Obstacle{
+		Id:       int32(o.ID),
+		X:        o.X,
+		Y:        o.Y,
+		Radius:   o.Radius,
+		Vertices: vertices,
+	}
+}
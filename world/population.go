@@ -0,0 +1,210 @@
+package world
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"os"
+)
+
+// Population makes threats come and go while the world runs. Births are a
+// Poisson process: each new threat enters at a random point on the world's
+// edge heading inwards, or anywhere in a spawn zone, and makes for a random
+// target if there are any. Born threats die when their lifetime is up; any
+// threat dies on leaving the area or reaching a target. The zero value
+// changes nothing.
+type Population struct {
+	BirthRate    float64    `json:"birth_rate"`            // Expected births per tick
+	MaxThreats   int        `json:"max_threats,omitempty"` // No births while this many are alive, 0 for no limit
+	SpawnZones   []Zone     `json:"spawn_zones,omitempty"` // Where births happen; none for the world's edges
+	MinSpeed     float64    `json:"min_speed"`             // Units per tick; both 0 for 0.5-2
+	MaxSpeed     float64    `json:"max_speed"`
+	MinLevel     int        `json:"min_level"` // Both 0 for 1-10
+	MaxLevel     int        `json:"max_level"`
+	Motion       MotionSpec `json:"motion"`                  // Model for born threats
+	MeanLifetime float64    `json:"mean_lifetime,omitempty"` // Mean of born threats' exponential lifetime in ticks, 0 for ever
+	Area         *Zone      `json:"area,omitempty"`          // Threats leaving it die
+	Targets      []Target   `json:"targets,omitempty"`       // Threats reaching one die
+}
+
+// Zone is a rectangle with its lower corner at (X, Y)
+type Zone struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// Target is a circle threats are headed for, and leave the world on reaching
+type Target struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Radius float64 `json:"radius"`
+}
+
+// LoadPopulation reads a JSON population config. Unknown fields are errors,
+// reported with their line like a scenario's.
+func LoadPopulation(path string) (Population, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Population{}, err
+	}
+
+	var p Population
+	if err := decodeStrict(path, data, &p); err != nil {
+		return Population{}, err
+	}
+	return p, nil
+}
+
+// validate fills in defaults and checks the config against the world's size
+func (p *Population) validate(width, height float64) error {
+	if p.BirthRate < 0 || p.MaxThreats < 0 || p.MeanLifetime < 0 {
+		return fmt.Errorf("birth_rate, max_threats and mean_lifetime must not be negative")
+	}
+	if p.MinSpeed == 0 && p.MaxSpeed == 0 {
+		p.MinSpeed, p.MaxSpeed = 0.5, 2
+	}
+	if p.MinSpeed < 0 || p.MaxSpeed < p.MinSpeed {
+		return fmt.Errorf("speed range %g-%g is invalid", p.MinSpeed, p.MaxSpeed)
+	}
+	if p.MinLevel == 0 && p.MaxLevel == 0 {
+		p.MinLevel, p.MaxLevel = 1, 10
+	}
+	if p.MinLevel < 1 || p.MaxLevel > 10 || p.MaxLevel < p.MinLevel {
+		return fmt.Errorf("level range %d-%d is not within 1-10", p.MinLevel, p.MaxLevel)
+	}
+	if err := p.Motion.validate(); err != nil {
+		return fmt.Errorf("motion: %w", err)
+	}
+
+	for i, z := range p.SpawnZones {
+		if err := z.validate(width, height); err != nil {
+			return fmt.Errorf("spawn_zones[%d]: %w", i, err)
+		}
+	}
+	if p.Area != nil {
+		if err := p.Area.validate(width, height); err != nil {
+			return fmt.Errorf("area: %w", err)
+		}
+	}
+	for i, t := range p.Targets {
+		if t.Radius <= 0 {
+			return fmt.Errorf("targets[%d]: radius must be positive", i)
+		}
+	}
+	return nil
+}
+
+func (z Zone) validate(width, height float64) error {
+	if z.Width <= 0 || z.Height <= 0 {
+		return fmt.Errorf("width and height must be positive")
+	}
+	if z.X < 0 || z.Y < 0 || z.X+z.Width > width || z.Y+z.Height > height {
+		return fmt.Errorf("zone outside the %gx%g world", width, height)
+	}
+	return nil
+}
+
+func (z Zone) contains(x, y float64) bool {
+	return x >= z.X && x <= z.X+z.Width && y >= z.Y && y <= z.Y+z.Height
+}
+
+// populate runs the population's deaths and then its births for this tick
+func (w *World) populate() {
+	p := &w.Population
+
+	kept := w.Threats[:0]
+	for _, t := range w.Threats {
+		if p.Area != nil && !p.Area.contains(t.X, t.Y) {
+			continue
+		}
+		if w.reachedTarget(t) {
+			continue
+		}
+		kept = append(kept, t)
+	}
+	w.Threats = kept
+
+	for n := poisson(w.rng, p.BirthRate); n > 0; n-- {
+		if p.MaxThreats > 0 && len(w.Threats) >= p.MaxThreats {
+			break
+		}
+		if w.nextID > MaxThreatID {
+			break // Out of IDs, and they are never reused
+		}
+		w.Threats = append(w.Threats, w.birth())
+	}
+}
+
+func (w *World) reachedTarget(t Threat) bool {
+	for _, target := range w.Population.Targets {
+		dx := w.delta(t.X, target.X, w.Width)
+		dy := w.delta(t.Y, target.Y, w.Height)
+		if math.Hypot(dx, dy) <= target.Radius {
+			return true
+		}
+	}
+	return false
+}
+
+// birth creates a threat under a new ID, placed and aimed as the
+// population says
+func (w *World) birth() Threat {
+	p := &w.Population
+
+	var x, y, heading float64
+	if len(p.SpawnZones) > 0 {
+		z := p.SpawnZones[w.rng.IntN(len(p.SpawnZones))]
+		x, y = z.X+w.rng.Float64()*z.Width, z.Y+w.rng.Float64()*z.Height
+		heading = w.rng.Float64() * 2 * math.Pi
+	} else {
+		// A point on the perimeter, heading within 60° of straight in
+		var inward float64
+		d := w.rng.Float64() * 2 * (w.Width + w.Height)
+		switch {
+		case d < w.Width:
+			x, y, inward = d, 0, math.Pi/2
+		case d < 2*w.Width:
+			x, y, inward = d-w.Width, math.Nextafter(w.Height, 0), -math.Pi/2
+		case d < 2*w.Width+w.Height:
+			x, y, inward = 0, d-2*w.Width, 0
+		default:
+			x, y, inward = math.Nextafter(w.Width, 0), d-2*w.Width-w.Height, math.Pi
+		}
+		heading = inward + (w.rng.Float64()*2-1)*math.Pi/3
+	}
+	if len(p.Targets) > 0 {
+		target := p.Targets[w.rng.IntN(len(p.Targets))]
+		heading = math.Atan2(w.delta(y, target.Y, w.Height), w.delta(x, target.X, w.Width))
+	}
+
+	speed := p.MinSpeed + w.rng.Float64()*(p.MaxSpeed-p.MinSpeed)
+	t := Threat{
+		X:      x,
+		Y:      y,
+		VX:     speed * math.Cos(heading),
+		VY:     speed * math.Sin(heading),
+		ID:     w.nextID,
+		Level:  p.MinLevel + w.rng.IntN(p.MaxLevel-p.MinLevel+1),
+		motion: p.Motion.newModel(),
+	}
+	w.nextID++
+	if p.MeanLifetime > 0 {
+		t.despawnTick = w.Tick + max(1, int(math.Round(w.rng.ExpFloat64()*p.MeanLifetime)))
+	}
+	return t
+}
+
+// poisson draws from a Poisson distribution with the given mean
+func poisson(rng *rand.Rand, mean float64) int {
+	if mean <= 0 {
+		return 0
+	}
+	limit, n, prod := math.Exp(-mean), 0, rng.Float64()
+	for prod > limit {
+		n++
+		prod *= rng.Float64()
+	}
+	return n
+}
@@ -0,0 +1,236 @@
+package world
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Boundary modes
+const (
+	BoundaryTorus   = "torus"   // Threats leaving one edge reappear at the opposite one
+	BoundaryReflect = "reflect" // Threats bounce off the edges
+	BoundaryAbsorb  = "absorb"  // Threats leaving the world are gone
+)
+
+// Scenario is a scripted world: its size and edges, the threats in it and
+// what happens to them when. Times are world ticks.
+type Scenario struct {
+	Width      float64          `json:"width"`
+	Height     float64          `json:"height"`
+	Boundary   string           `json:"boundary"` // Default torus
+	Threats    []ScenarioThreat `json:"threats"`
+	Obstacles  []Obstacle       `json:"obstacles,omitempty"`
+	Population Population       `json:"population"` // Births and deaths on top of the scripted threats
+}
+
+// ScenarioThreat is one threat's initial state and script. Threats with a
+// SpawnTick after 0 appear later; a DespawnTick removes them.
+type ScenarioThreat struct {
+	ID           int           `json:"id"` // Unique; 0 or missing is numbered after the highest given
+	X            float64       `json:"x"`
+	Y            float64       `json:"y"`
+	VX           float64       `json:"vx"` // Units per tick
+	VY           float64       `json:"vy"`
+	Level        int           `json:"level"`
+	SpawnTick    int           `json:"spawn_tick"`
+	DespawnTick  int           `json:"despawn_tick"` // 0 for never
+	Motion       MotionSpec    `json:"motion"`
+	LevelChanges []LevelChange `json:"level_changes,omitempty"`
+}
+
+// LevelChange sets a threat's level from a tick on
+type LevelChange struct {
+	Tick  int `json:"tick"`
+	Level int `json:"level"`
+}
+
+// LoadScenario reads and validates a JSON scenario. Errors name the file
+// and, where there is one, the offending entry or line.
+func LoadScenario(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	sc := &Scenario{Width: 100, Height: 100}
+	if err := decodeStrict(path, data, sc); err != nil {
+		return nil, err
+	}
+
+	if err := sc.normalize(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return sc, nil
+}
+
+// decodeStrict decodes a config file's JSON into v, rejecting unknown
+// fields. Errors name the file and, where the decoder gives a position,
+// the line.
+func decodeStrict(path string, data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		var syntax *json.SyntaxError
+		var typ *json.UnmarshalTypeError
+		switch {
+		case errors.As(err, &syntax):
+			return fmt.Errorf("%s:%d: %w", path, lineOf(data, syntax.Offset), err)
+		case errors.As(err, &typ):
+			return fmt.Errorf("%s:%d: %s: expected %s", path, lineOf(data, typ.Offset), fieldPath(typ.Field), typ.Type)
+		case strings.HasPrefix(err.Error(), "json: unknown field "):
+			// The decoder doesn't say where; the first use is a good guess
+			name := strings.TrimPrefix(err.Error(), "json: unknown field ")
+			if at := bytes.Index(data, []byte(name)); at >= 0 {
+				return fmt.Errorf("%s:%d: unknown field %s", path, lineOf(data, int64(at)), name)
+			}
+		}
+		return fmt.Errorf("parse %s: %w", path, err)
+	}
+	return nil
+}
+
+// lineOf turns a byte offset into a 1-based line number
+func lineOf(data []byte, offset int64) int {
+	return bytes.Count(data[:min(int(offset), len(data))], []byte("\n")) + 1
+}
+
+// fieldPath turns the decoder's "threats.2.x" into "threats[2].x"
+func fieldPath(field string) string {
+	parts := strings.Split(field, ".")
+	var b strings.Builder
+	for i, p := range parts {
+		if _, err := strconv.Atoi(p); err == nil {
+			fmt.Fprintf(&b, "[%s]", p)
+			continue
+		}
+		if i > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(p)
+	}
+	return b.String()
+}
+
+// normalize fills in defaults and checks every entry
+func (sc *Scenario) normalize() error {
+	if sc.Width <= 0 || sc.Height <= 0 {
+		return fmt.Errorf("width and height must be positive")
+	}
+	if sc.Boundary == "" {
+		sc.Boundary = BoundaryTorus
+	}
+	if err := validBoundary(sc.Boundary); err != nil {
+		return fmt.Errorf("boundary: %w", err)
+	}
+
+	nextID := 1
+	for _, t := range sc.Threats {
+		nextID = max(nextID, t.ID+1)
+	}
+	seen := make(map[int]int)
+	for i := range sc.Threats {
+		t := &sc.Threats[i]
+		if t.ID == 0 {
+			t.ID = nextID
+			nextID++
+		}
+		if j, dup := seen[t.ID]; dup {
+			return fmt.Errorf("threats[%d]: duplicate id %d (also threats[%d])", i, t.ID, j)
+		}
+		seen[t.ID] = i
+
+		if err := t.validate(sc.Width, sc.Height, sc.Boundary != BoundaryTorus); err != nil {
+			return fmt.Errorf("threats[%d] (id %d): %w", i, t.ID, err)
+		}
+	}
+
+	for i := range sc.Obstacles {
+		if sc.Obstacles[i].ID == 0 {
+			sc.Obstacles[i].ID = i + 1
+		}
+		if err := sc.Obstacles[i].validate(); err != nil {
+			return fmt.Errorf("obstacles[%d]: %w", i, err)
+		}
+	}
+
+	if err := sc.Population.validate(sc.Width, sc.Height); err != nil {
+		return fmt.Errorf("population: %w", err)
+	}
+	return nil
+}
+
+func validBoundary(mode string) error {
+	switch mode {
+	case BoundaryTorus, BoundaryReflect, BoundaryAbsorb:
+		return nil
+	}
+	return fmt.Errorf("unknown mode %q", mode)
+}
+
+func (t *ScenarioThreat) validate(width, height float64, bounded bool) error {
+	if t.ID < 0 {
+		return fmt.Errorf("id must be positive")
+	}
+	if t.ID > MaxThreatID {
+		return fmt.Errorf("id %d is above %d", t.ID, MaxThreatID)
+	}
+	if !onAxis(t.X, width, bounded) || !onAxis(t.Y, height, bounded) {
+		return fmt.Errorf("position (%g, %g) outside the %gx%g world", t.X, t.Y, width, height)
+	}
+	if t.Level < 1 || t.Level > 10 {
+		return fmt.Errorf("level %d out of range 1-10", t.Level)
+	}
+	if t.SpawnTick < 0 {
+		return fmt.Errorf("spawn_tick must not be negative")
+	}
+	if t.DespawnTick != 0 && t.DespawnTick <= t.SpawnTick {
+		return fmt.Errorf("despawn_tick %d is not after spawn_tick %d", t.DespawnTick, t.SpawnTick)
+	}
+	if err := t.Motion.validate(); err != nil {
+		return fmt.Errorf("motion: %w", err)
+	}
+
+	for i, lc := range t.LevelChanges {
+		if lc.Level < 1 || lc.Level > 10 {
+			return fmt.Errorf("level_changes[%d]: level %d out of range 1-10", i, lc.Level)
+		}
+		if lc.Tick <= t.SpawnTick {
+			return fmt.Errorf("level_changes[%d]: tick %d is not after spawn_tick %d", i, lc.Tick, t.SpawnTick)
+		}
+		if t.DespawnTick != 0 && lc.Tick >= t.DespawnTick {
+			return fmt.Errorf("level_changes[%d]: tick %d is not before despawn_tick %d", i, lc.Tick, t.DespawnTick)
+		}
+	}
+	sort.SliceStable(t.LevelChanges, func(i, j int) bool {
+		return t.LevelChanges[i].Tick < t.LevelChanges[j].Tick
+	})
+	return nil
+}
+
+// NewWorld builds the scenario's world at tick 0
+func (sc *Scenario) NewWorld() *World {
+	w := &World{
+		Width:      sc.Width,
+		Height:     sc.Height,
+		Boundary:   sc.Boundary,
+		Population: sc.Population,
+		pending:    append([]ScenarioThreat(nil), sc.Threats...),
+		source:     newSource(),
+	}
+	w.rng = rand.New(w.source)
+	for _, t := range sc.Threats {
+		w.nextID = max(w.nextID, t.ID+1)
+	}
+	sort.SliceStable(w.pending, func(i, j int) bool {
+		return w.pending[i].SpawnTick < w.pending[j].SpawnTick
+	})
+	w.runScript()
+	return w
+}
@@ -2,9 +2,11 @@ package world
 
 import (
 	"encoding/json"
+	"fmt"
 	"log"
 	"net"
 	"net/http"
+	"strconv"
 	"sync"
 	"time"
 
@@ -13,119 +15,326 @@ import (
 	"google.golang.org/grpc"
 )
 
+// maxStepsPerRequest caps /step, which holds the request until every tick
+// has been delivered
+const maxStepsPerRequest = 10000
+
 type WorldServer struct {
 	pb.UnimplementedWorldServiceServer
 	world       *World
 	mu          sync.RWMutex
-	subscribers []chan *pb.WorldState
+	subscribers []*subscriber
 	subMu       sync.Mutex
+	sendMu      sync.Mutex // Held from building a state until it is queued, so states go out in tick order
+	obstacles   []Obstacle
+	scenario    *Scenario // Replayed on restart instead of random threats
+	snapshot    *Snapshot // Last snapshot loaded, restored on restart instead of the scenario
+	epoch       int32     // Counts replacements of the world, see pb.WorldState.Epoch
+	steppedAt   time.Time // When the world reached its current tick, see pb.WorldState.Timestamp
+	population  Population
+	boundary    string
+
+	paused      bool
+	fastForward bool // Tick as fast as subscribers take states, ignoring tickRate
+	pausedMu    sync.RWMutex
+	wake        chan struct{} // Tells RunSimulation the pace has changed
+	numThreats  int
+	width       float64
+	height      float64
+	tickRate    time.Duration
+}
 
-	paused     bool
-	pausedMu   sync.RWMutex
-	numThreats int
-	width      float64
-	height     float64
-	tickRate   time.Duration
+// subscriber is one Subscribe stream's queue of world states
+type subscriber struct {
+	ch   chan *pb.WorldState
+	done chan struct{} // Closed once the stream has ended
 }
 
 func NewWorldServer(numThreats int, width, height float64) *WorldServer {
 	return &WorldServer{
 		world:       NewWorld(numThreats, width, height),
-		subscribers: make([]chan *pb.WorldState, 0),
+		subscribers: make([]*subscriber, 0),
 		paused:      false,
+		wake:        make(chan struct{}, 1),
 		numThreats:  numThreats,
 		width:       width,
 		height:      height,
+		boundary:    BoundaryTorus,
+		steppedAt:   time.Now(),
+	}
+}
+
+// NewWorldServerFromScenario serves a scripted world, with the scenario's
+// obstacles
+func NewWorldServerFromScenario(sc *Scenario) *WorldServer {
+	return &WorldServer{
+		world:       sc.NewWorld(),
+		subscribers: make([]*subscriber, 0),
+		wake:        make(chan struct{}, 1),
+		obstacles:   sc.Obstacles,
+		scenario:    sc,
+		population:  sc.Population,
+		boundary:    sc.Boundary,
+		width:       sc.Width,
+		height:      sc.Height,
+		steppedAt:   time.Now(),
 	}
 }
 
 func (s *WorldServer) Subscribe(req *pb.SubscribeRequest, stream pb.WorldService_SubscribeServer) error {
-	ch := make(chan *pb.WorldState, 10)
+	sub := &subscriber{
+		ch:   make(chan *pb.WorldState, 10),
+		done: make(chan struct{}),
+	}
 
 	s.subMu.Lock()
-	s.subscribers = append(s.subscribers, ch)
+	s.subscribers = append(s.subscribers, sub)
 	s.subMu.Unlock()
 
 	defer func() {
+		close(sub.done)
 		s.subMu.Lock()
-		for i, sub := range s.subscribers {
-			if sub == ch {
+		for i, other := range s.subscribers {
+			if other == sub {
 				s.subscribers = append(s.subscribers[:i], s.subscribers[i+1:]...)
 				break
 			}
 		}
 		s.subMu.Unlock()
-		close(ch)
 	}()
 
-	for state := range ch {
-		if err := stream.Send(state); err != nil {
-			return err
+	for {
+		select {
+		case state := <-sub.ch:
+			if err := stream.Send(state); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return nil
 		}
 	}
-	return nil
 }
 
-func (s *WorldServer) broadcast() {
+// broadcast sends the current state to every subscriber. A subscriber
+// that is behind misses it, unless wait is set, in which case broadcast
+// blocks until each one has room. Broadcasts don't overlap, so a paused
+// world's rebroadcast can't deliver a tick after the one Step just sent.
+func (s *WorldServer) broadcast(wait bool) {
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+
 	s.mu.RLock()
 	state := s.buildState()
 	s.mu.RUnlock()
 
 	s.subMu.Lock()
-	for _, ch := range s.subscribers {
+	subscribers := append([]*subscriber(nil), s.subscribers...)
+	s.subMu.Unlock()
+
+	for _, sub := range subscribers {
+		if wait {
+			select {
+			case sub.ch <- state:
+			case <-sub.done:
+			}
+			continue
+		}
 		select {
-		case ch <- state:
+		case sub.ch <- state:
 		default:
 		}
 	}
-	s.subMu.Unlock()
 }
 
 func (s *WorldServer) buildState() *pb.WorldState {
 	threats := make([]*pb.Threat, len(s.world.Threats))
 	for i, t := range s.world.Threats {
 		threats[i] = &pb.Threat{
-			Id:    int32(t.ID),
-			X:     t.X,
-			Y:     t.Y,
-			Vx:    t.VX,
-			Vy:    t.VY,
-			Level: int32(t.Level),
+			Id:     int32(t.ID),
+			X:      t.X,
+			Y:      t.Y,
+			Vx:     t.VX,
+			Vy:     t.VY,
+			Level:  int32(t.Level),
+			Motion: motionToProto(t.Motion()),
 		}
 	}
+	obstacles := make([]*pb.Obstacle, len(s.obstacles))
+	for i, o := range s.obstacles {
+		obstacles[i] = o.toProto()
+	}
 	return &pb.WorldState{
-		Threats: threats,
-		Tick:    int32(s.world.Tick),
+		Threats:   threats,
+		Tick:      int32(s.world.Tick),
+		Width:     s.world.Width,
+		Height:    s.world.Height,
+		Obstacles: obstacles,
+		Boundary:  s.world.Boundary,
+		Epoch:     s.epoch,
+		Timestamp: s.steppedAt.UnixNano(),
+	}
+}
+
+// SetObstacles replaces the static obstacles published to subscribers
+func (s *WorldServer) SetObstacles(obstacles []Obstacle) error {
+	for i, o := range obstacles {
+		if err := o.validate(); err != nil {
+			return fmt.Errorf("obstacle %d: %w", i, err)
+		}
 	}
+
+	s.mu.Lock()
+	s.obstacles = obstacles
+	s.mu.Unlock()
+
+	log.Printf("Loaded %d obstacles", len(obstacles))
+	return nil
 }
 
+// SetBoundary sets what happens to threats at the world's edges, from now
+// on and after restarts
+func (s *WorldServer) SetBoundary(mode string) error {
+	if err := validBoundary(mode); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.boundary = mode
+	s.world.Boundary = mode
+	s.mu.Unlock()
+
+	log.Printf("Boundary: %s", mode)
+	return nil
+}
+
+// SetPopulation sets how threats are born and die while the world runs,
+// from now on and after restarts
+func (s *WorldServer) SetPopulation(p Population) error {
+	s.mu.Lock()
+	if err := p.validate(s.width, s.height); err != nil {
+		s.mu.Unlock()
+		return err
+	}
+	s.population = p
+	s.world.Population = p
+	s.mu.Unlock()
+
+	log.Printf("Population: %.2g births per tick", p.BirthRate)
+	return nil
+}
+
+// Obstacles returns the current static obstacles
+func (s *WorldServer) Obstacles() []Obstacle {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]Obstacle(nil), s.obstacles...)
+}
+
+// RunSimulation steps the world every tick and publishes each state; while
+// paused it keeps publishing the same one. Changes to the pace through
+// SetSpeed, SetFastForward, Pause and Resume apply at once rather than at
+// the next tick.
 func (s *WorldServer) RunSimulation(tickRate time.Duration) {
 	s.pausedMu.Lock()
 	s.tickRate = tickRate
 	s.pausedMu.Unlock()
 
-	ticker := time.NewTicker(tickRate)
-	defer ticker.Stop()
+	timer := time.NewTimer(tickRate)
+	defer timer.Stop()
 
-	for range ticker.C {
+	for {
 		s.pausedMu.RLock()
-		paused := s.paused
+		paused, fast, rate := s.paused, s.fastForward, s.tickRate
 		s.pausedMu.RUnlock()
 
-		if !paused {
-			s.mu.Lock()
-			s.world.Step()
-			s.mu.Unlock()
+		if fast && !paused && s.hasSubscribers() {
+			// No waiting between ticks: the subscribers set the pace
+			s.step()
+			s.broadcast(true)
+			continue
 		}
 
-		s.broadcast()
+		select {
+		case <-timer.C:
+			if !paused {
+				s.step()
+			}
+			s.broadcast(false)
+		case <-s.wake:
+			// Start the wait over at the new pace
+		}
+		timer.Reset(rate)
 	}
 }
 
+func (s *WorldServer) hasSubscribers() bool {
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+	return len(s.subscribers) > 0
+}
+
+func (s *WorldServer) step() {
+	s.mu.Lock()
+	s.world.Step()
+	s.steppedAt = time.Now()
+	s.mu.Unlock()
+}
+
+// reschedule wakes RunSimulation so a change of pace takes effect now
+func (s *WorldServer) reschedule() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Step advances a paused world n ticks, delivering every state to
+// subscribers before moving on
+func (s *WorldServer) Step(n int) error {
+	if !s.IsPaused() {
+		return fmt.Errorf("simulation is running; pause it first")
+	}
+	for i := 0; i < n; i++ {
+		s.step()
+		s.broadcast(true)
+	}
+	log.Printf("Stepped %d ticks", n)
+	return nil
+}
+
+// SetSpeed sets the time between ticks, leaving fast-forward
+func (s *WorldServer) SetSpeed(interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("tick interval must be positive")
+	}
+
+	s.pausedMu.Lock()
+	s.tickRate = interval
+	s.fastForward = false
+	s.pausedMu.Unlock()
+	s.reschedule()
+
+	log.Printf("Tick interval set to %v", interval)
+	return nil
+}
+
+// SetFastForward switches to ticking as fast as subscribers can take the
+// states, or back to the tick interval. With no subscribers it keeps to the
+// interval.
+func (s *WorldServer) SetFastForward(on bool) {
+	s.pausedMu.Lock()
+	s.fastForward = on
+	s.pausedMu.Unlock()
+	s.reschedule()
+
+	log.Printf("Fast-forward: %v", on)
+}
+
 func (s *WorldServer) Pause() {
 	s.pausedMu.Lock()
 	defer s.pausedMu.Unlock()
 	s.paused = true
+	s.reschedule()
 	log.Println("Simulation paused")
 }
 
@@ -133,6 +342,7 @@ func (s *WorldServer) Resume() {
 	s.pausedMu.Lock()
 	defer s.pausedMu.Unlock()
 	s.paused = false
+	s.reschedule()
 	log.Println("Simulation resumed")
 }
 
@@ -140,14 +350,73 @@ func (s *WorldServer) Restart() {
 	s.pausedMu.Lock()
 	s.paused = false
 	s.pausedMu.Unlock()
+	s.reschedule()
 
 	s.mu.Lock()
-	s.world = NewWorld(s.numThreats, s.width, s.height)
+	switch {
+	case s.snapshot != nil:
+		// Checked when it was loaded, so it can't fail now
+		w, err := s.snapshot.World()
+		if err != nil {
+			log.Printf("Failed to restore snapshot: %v", err)
+			w = NewWorld(s.numThreats, s.width, s.height)
+		}
+		s.world = w
+	case s.scenario != nil:
+		s.world = s.scenario.NewWorld()
+	default:
+		s.world = NewWorld(s.numThreats, s.width, s.height)
+	}
+	s.world.Boundary = s.boundary
+	s.world.Population = s.population
+	s.epoch++
+	s.steppedAt = time.Now()
 	s.mu.Unlock()
 
 	log.Println("Simulation restarted")
 }
 
+// Snapshot captures the running world, obstacles included
+func (s *WorldServer) Snapshot() (*Snapshot, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sn, err := s.world.Snapshot()
+	if err != nil {
+		return nil, err
+	}
+	sn.Obstacles = append([]Obstacle(nil), s.obstacles...)
+	return sn, nil
+}
+
+// LoadSnapshot replaces the running world and obstacles with a snapshot's
+// in one go, then tells subscribers, since what they knew no longer
+// applies. The snapshot's size, edges and population become the server's,
+// and a restart goes back to the snapshot. A bad snapshot leaves the world
+// untouched.
+func (s *WorldServer) LoadSnapshot(sn *Snapshot) error {
+	w, err := sn.World()
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.world = w
+	s.obstacles = sn.Obstacles
+	s.snapshot = sn
+	s.width, s.height = w.Width, w.Height
+	s.boundary = w.Boundary
+	s.population = w.Population
+	s.epoch++
+	s.steppedAt = time.Now()
+	s.mu.Unlock()
+
+	log.Printf("Loaded snapshot at tick %d: %d threats in a %gx%g world",
+		w.Tick, len(w.Threats), w.Width, w.Height)
+	s.broadcast(true)
+	return nil
+}
+
 func (s *WorldServer) IsPaused() bool {
 	s.pausedMu.RLock()
 	defer s.pausedMu.RUnlock()
@@ -173,7 +442,7 @@ func (s *WorldServer) StartControlServer(port string) {
 	withCORS := func(h http.HandlerFunc) http.HandlerFunc {
 		return func(w http.ResponseWriter, r *http.Request) {
 			w.Header().Set("Access-Control-Allow-Origin", "*")
-			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PATCH, DELETE, OPTIONS")
 			w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
 			if r.Method == "OPTIONS" {
 				w.WriteHeader(http.StatusOK)
@@ -198,10 +467,82 @@ func (s *WorldServer) StartControlServer(port string) {
 		s.writeStatus(w)
 	}))
 
+	mux.HandleFunc("/step", withCORS(func(w http.ResponseWriter, r *http.Request) {
+		n := 1
+		if v := r.URL.Query().Get("n"); v != "" {
+			var err error
+			if n, err = strconv.Atoi(v); err != nil || n < 1 || n > maxStepsPerRequest {
+				writeError(w, http.StatusBadRequest, "n", "must be a whole number from 1 to %d", maxStepsPerRequest)
+				return
+			}
+		}
+		if err := s.Step(n); err != nil {
+			writeError(w, http.StatusConflict, "", "%v", err)
+			return
+		}
+		s.writeStatus(w)
+	}))
+
+	mux.HandleFunc("/speed", withCORS(func(w http.ResponseWriter, r *http.Request) {
+		if v := r.URL.Query().Get("interval"); v != "" {
+			interval, err := time.ParseDuration(v)
+			if err == nil {
+				err = s.SetSpeed(interval)
+			}
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "interval", "%v", err)
+				return
+			}
+		}
+		if v := r.URL.Query().Get("fast"); v != "" {
+			on, err := strconv.ParseBool(v)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "fast", "must be true or false")
+				return
+			}
+			s.SetFastForward(on)
+		}
+		s.writeStatus(w)
+	}))
+
+	mux.HandleFunc("/snapshot", withCORS(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			sn, err := s.Snapshot()
+			if err != nil {
+				writeError(w, http.StatusInternalServerError, "", "%v", err)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="world-%d.json"`, sn.Tick))
+			json.NewEncoder(w).Encode(sn)
+		case http.MethodPost:
+			var sn Snapshot
+			if !decodeBody(w, r, &sn) {
+				return
+			}
+			if err := s.LoadSnapshot(&sn); err != nil {
+				writeError(w, http.StatusUnprocessableEntity, "", "%v", err)
+				return
+			}
+			s.writeStatus(w)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "", "use GET to save or POST to load")
+		}
+	}))
+
+	mux.HandleFunc("/threats", withCORS(s.handleThreats))
+	mux.HandleFunc("/threats/{id}", withCORS(s.handleThreat))
+
 	mux.HandleFunc("/status", withCORS(func(w http.ResponseWriter, r *http.Request) {
 		s.writeStatus(w)
 	}))
 
+	mux.HandleFunc("/obstacles", withCORS(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(s.Obstacles())
+	}))
+
 	log.Printf("Control server listening on %s", port)
 	if err := http.ListenAndServe(port, mux); err != nil {
 		log.Fatalf("Control server error: %v", err)
@@ -210,12 +551,13 @@ func (s *WorldServer) StartControlServer(port string) {
 
 func (s *WorldServer) writeStatus(w http.ResponseWriter) {
 	s.pausedMu.RLock()
-	paused := s.paused
+	paused, fast, rate := s.paused, s.fastForward, s.tickRate
 	s.pausedMu.RUnlock()
 
 	s.mu.RLock()
 	tick := s.world.Tick
 	threatCount := len(s.world.Threats)
+	boundary := s.world.Boundary
 	s.mu.RUnlock()
 
 	state := "running"
@@ -225,8 +567,11 @@ func (s *WorldServer) writeStatus(w http.ResponseWriter) {
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"state":   state,
-		"tick":    tick,
-		"threats": threatCount,
+		"state":         state,
+		"tick":          tick,
+		"threats":       threatCount,
+		"boundary":      boundary,
+		"tick_interval": rate.String(),
+		"fast_forward":  fast,
 	})
 }
@@ -0,0 +1,186 @@
+package world
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"sort"
+)
+
+// SnapshotVersion is the snapshot format written by this build. Loading
+// any other version is refused rather than half understood.
+const SnapshotVersion = 1
+
+// Snapshot is the complete state of a running world as a JSON document:
+// everything needed to carry on exactly where it left off, including the
+// random generator and each threat's motion model state
+type Snapshot struct {
+	Version    int              `json:"version"`
+	Tick       int              `json:"tick"`
+	Width      float64          `json:"width"`
+	Height     float64          `json:"height"`
+	Boundary   string           `json:"boundary"`
+	Threats    []ThreatSnapshot `json:"threats"`
+	Pending    []ScenarioThreat `json:"pending,omitempty"` // Scenario threats yet to spawn
+	NextID     int              `json:"next_id"`           // Next unused threat ID
+	Population Population       `json:"population"`
+	Obstacles  []Obstacle       `json:"obstacles,omitempty"`
+	RNG        []byte           `json:"rng,omitempty"` // Random generator state; missing for a fresh seed
+}
+
+// ThreatSnapshot is a live threat: its current state, what its script
+// still has in store, and its motion model's state
+type ThreatSnapshot struct {
+	ScenarioThreat
+	MotionState map[string]float64 `json:"motion_state,omitempty"`
+}
+
+// Snapshot captures the world as of the current tick. Obstacles belong to
+// the server and are left for it to fill in.
+func (w *World) Snapshot() (*Snapshot, error) {
+	rng, err := w.source.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("rng: %w", err)
+	}
+
+	sn := &Snapshot{
+		Version:    SnapshotVersion,
+		Tick:       w.Tick,
+		Width:      w.Width,
+		Height:     w.Height,
+		Boundary:   w.Boundary,
+		Threats:    make([]ThreatSnapshot, 0, len(w.Threats)),
+		Pending:    append([]ScenarioThreat(nil), w.pending...),
+		NextID:     w.nextID,
+		Population: w.Population,
+		RNG:        rng,
+	}
+	for _, t := range w.Threats {
+		m, ok := t.Motion().(savedMotion)
+		if !ok {
+			return nil, fmt.Errorf("threat %d: motion model %s can't be saved", t.ID, t.Motion().Name())
+		}
+		sn.Threats = append(sn.Threats, ThreatSnapshot{
+			ScenarioThreat: ScenarioThreat{
+				ID:           t.ID,
+				X:            t.X,
+				Y:            t.Y,
+				VX:           t.VX,
+				VY:           t.VY,
+				Level:        t.Level,
+				DespawnTick:  t.despawnTick,
+				Motion:       m.spec(),
+				LevelChanges: append([]LevelChange(nil), t.levelChanges...),
+			},
+			MotionState: m.state(),
+		})
+	}
+	return sn, nil
+}
+
+// World rebuilds the snapshot's world, checking it as thoroughly as a
+// scenario. Errors name the offending entry.
+func (sn *Snapshot) World() (*World, error) {
+	if sn.Version != SnapshotVersion {
+		return nil, fmt.Errorf("version %d not supported, want %d", sn.Version, SnapshotVersion)
+	}
+	if sn.Width <= 0 || sn.Height <= 0 {
+		return nil, fmt.Errorf("width and height must be positive")
+	}
+	boundary := sn.Boundary
+	if boundary == "" {
+		boundary = BoundaryTorus
+	}
+	if err := validBoundary(boundary); err != nil {
+		return nil, fmt.Errorf("boundary: %w", err)
+	}
+	if sn.Tick < 0 {
+		return nil, fmt.Errorf("tick must not be negative")
+	}
+	if sn.NextID < 0 || sn.NextID > MaxThreatID+1 {
+		return nil, fmt.Errorf("next_id %d is outside 0-%d", sn.NextID, MaxThreatID+1)
+	}
+
+	w := &World{
+		Tick:       sn.Tick,
+		Width:      sn.Width,
+		Height:     sn.Height,
+		Boundary:   boundary,
+		Population: sn.Population,
+		nextID:     sn.NextID,
+		pending:    append([]ScenarioThreat(nil), sn.Pending...),
+	}
+	if err := w.Population.validate(w.Width, w.Height); err != nil {
+		return nil, fmt.Errorf("population: %w", err)
+	}
+	for i := range sn.Obstacles {
+		if err := sn.Obstacles[i].validate(); err != nil {
+			return nil, fmt.Errorf("obstacles[%d]: %w", i, err)
+		}
+	}
+
+	seen := make(map[int]string)
+	claim := func(id int, entry string) error {
+		if other, dup := seen[id]; dup {
+			return fmt.Errorf("%s: duplicate id %d (also %s)", entry, id, other)
+		}
+		if id >= sn.NextID {
+			return fmt.Errorf("%s: id %d is not below next_id %d", entry, id, sn.NextID)
+		}
+		seen[id] = entry
+		return nil
+	}
+
+	for i, ts := range sn.Threats {
+		entry := fmt.Sprintf("threats[%d]", i)
+		if err := claim(ts.ID, entry); err != nil {
+			return nil, err
+		}
+		if err := ts.validate(w.Width, w.Height, w.Bounded()); err != nil {
+			return nil, fmt.Errorf("%s (id %d): %w", entry, ts.ID, err)
+		}
+		if ts.DespawnTick != 0 && ts.DespawnTick <= w.Tick {
+			return nil, fmt.Errorf("%s (id %d): despawn_tick %d is not after tick %d", entry, ts.ID, ts.DespawnTick, w.Tick)
+		}
+
+		motion := ts.Motion.newModel()
+		if m, ok := motion.(savedMotion); ok {
+			m.restore(ts.MotionState)
+		}
+		w.Threats = append(w.Threats, Threat{
+			X:            ts.X,
+			Y:            ts.Y,
+			VX:           ts.VX,
+			VY:           ts.VY,
+			ID:           ts.ID,
+			Level:        ts.Level,
+			motion:       motion,
+			despawnTick:  ts.DespawnTick,
+			levelChanges: ts.LevelChanges,
+		})
+	}
+	for i := range w.pending {
+		st := &w.pending[i]
+		entry := fmt.Sprintf("pending[%d]", i)
+		if err := claim(st.ID, entry); err != nil {
+			return nil, err
+		}
+		if err := st.validate(w.Width, w.Height, w.Bounded()); err != nil {
+			return nil, fmt.Errorf("%s (id %d): %w", entry, st.ID, err)
+		}
+		if st.SpawnTick <= w.Tick {
+			return nil, fmt.Errorf("%s (id %d): spawn_tick %d is not after tick %d", entry, st.ID, st.SpawnTick, w.Tick)
+		}
+	}
+	sort.SliceStable(w.pending, func(i, j int) bool {
+		return w.pending[i].SpawnTick < w.pending[j].SpawnTick
+	})
+
+	w.source = newSource()
+	if len(sn.RNG) > 0 {
+		if err := w.source.UnmarshalBinary(sn.RNG); err != nil {
+			return nil, fmt.Errorf("rng: %w", err)
+		}
+	}
+	w.rng = rand.New(w.source)
+	return w, nil
+}
@@ -1,9 +1,13 @@
 package world
 
 import (
-	"math/rand"
+	"math"
+	"math/rand/v2"
 )
 
+// MaxThreatID is the largest threat ID, since IDs go out as int32
+const MaxThreatID = math.MaxInt32
+
 type Threat struct {
 	X     float64
 	Y     float64
@@ -11,42 +15,180 @@ type Threat struct {
 	VY    float64
 	ID    int
 	Level int
+
+	motion       MotionModel   // Nil for constant velocity
+	despawnTick  int           // Scenario removal tick, 0 for never
+	levelChanges []LevelChange // Scenario level changes still to come
+}
+
+// Motion returns the threat's motion model
+func (t *Threat) Motion() MotionModel {
+	if t.motion == nil {
+		return constantVelocity{}
+	}
+	return t.motion
 }
 
 type World struct {
-	Threats []Threat
-	Tick    int
-	Width   float64
-	Height  float64
+	Threats    []Threat
+	Tick       int
+	Width      float64
+	Height     float64
+	Boundary   string
+	Population Population // Births and deaths while running
+
+	pending []ScenarioThreat // Scenario threats yet to spawn, by spawn tick
+	nextID  int              // Next unused threat ID; IDs are never reused
+	source  *rand.PCG        // The world's own randomness, so snapshots can save it
+	rng     *rand.Rand       // Draws from source
 }
 
 func NewWorld(numThreats int, width, height float64) *World {
+	source := newSource()
+	rng := rand.New(source)
 	return &World{
-		Threats: createThreats(numThreats, width, height),
-		Tick:    0,
-		Width:   width,
-		Height:  height,
+		Threats:  createThreats(rng, numThreats, width, height),
+		Tick:     0,
+		Width:    width,
+		Height:   height,
+		Boundary: BoundaryTorus,
+		nextID:   numThreats,
+		source:   source,
+		rng:      rng,
 	}
 }
 
-func createThreats(numThreats int, width, height float64) []Threat {
+// newSource returns a random source seeded from the global generator
+func newSource() *rand.PCG {
+	return rand.NewPCG(rand.Uint64(), rand.Uint64())
+}
+
+func createThreats(rng *rand.Rand, numThreats int, width, height float64) []Threat {
 	threats := make([]Threat, numThreats)
 	for i := 0; i < numThreats; i++ {
 		threats[i] = Threat{
-			X:     rand.Float64() * width,
-			Y:     rand.Float64() * height,
-			VX:    (rand.Float64() * 4) - 2,
-			VY:    (rand.Float64() * 4) - 2,
+			X:     rng.Float64() * width,
+			Y:     rng.Float64() * height,
+			VX:    (rng.Float64() * 4) - 2,
+			VY:    (rng.Float64() * 4) - 2,
 			ID:    i,
-			Level: rand.Intn(10) + 1,
+			Level: rng.IntN(10) + 1,
 		}
 	}
 	return threats
 }
 
 func (w *World) Step() {
-	for i := range w.Threats {
-		UpdatePosition(&w.Threats[i], w.Width, w.Height)
+	kept := w.Threats[:0]
+	for _, t := range w.Threats {
+		t.Motion().Steer(&t, w)
+		if w.move(&t) {
+			kept = append(kept, t)
+		}
 	}
+	w.Threats = kept
 	w.Tick++
+	w.runScript()
+	w.populate()
+}
+
+// Bounded reports whether the world has edges rather than wrapping round
+func (w *World) Bounded() bool {
+	return w.Boundary == BoundaryReflect || w.Boundary == BoundaryAbsorb
+}
+
+// onAxis reports whether v is a valid coordinate along an axis of the
+// given size. A torus wraps size round to 0, but a bounded world's far
+// edge is part of it.
+func onAxis(v, size float64, bounded bool) bool {
+	if bounded {
+		return v >= 0 && v <= size
+	}
+	return v >= 0 && v < size
+}
+
+// move advances a threat by its velocity and applies the world's
+// boundary. It reports false if the threat has left an absorbing world.
+func (w *World) move(t *Threat) bool {
+	switch w.Boundary {
+	case BoundaryReflect:
+		t.X, t.VX = reflect(t.X+t.VX, t.VX, w.Width)
+		t.Y, t.VY = reflect(t.Y+t.VY, t.VY, w.Height)
+	case BoundaryAbsorb:
+		t.X += t.VX
+		t.Y += t.VY
+		return t.X >= 0 && t.X <= w.Width && t.Y >= 0 && t.Y <= w.Height
+	default:
+		UpdatePosition(t, w.Width, w.Height)
+	}
+	return true
+}
+
+// reflect folds a position back into [0, size], reversing the velocity
+// once per bounce. However far out v is, the fold is one step: positions
+// repeat every 2*size, and an odd number of bounces reverses the velocity.
+func reflect(v, vel, size float64) (float64, float64) {
+	if v >= 0 && v <= size {
+		return v, vel
+	}
+	bounces := math.Ceil(v/size) - 1
+	if v < 0 {
+		bounces = math.Ceil(-v / size)
+	}
+	m := math.Abs(math.Mod(v, 2*size))
+	if m > size {
+		m = 2*size - m
+	}
+	if math.Mod(bounces, 2) == 1 {
+		vel = -vel
+	}
+	return m, vel
+}
+
+// delta returns the displacement from a to b along an axis of the given
+// size, the short way round on a torus, in (-size/2, size/2]
+func (w *World) delta(a, b, size float64) float64 {
+	if w.Bounded() {
+		return b - a
+	}
+	d := math.Mod(b-a, size)
+	if d > size/2 {
+		d -= size
+	} else if d <= -size/2 {
+		d += size
+	}
+	return d
+}
+
+// runScript applies what a scenario has scheduled up to the current tick:
+// removals, then level changes, then arrivals
+func (w *World) runScript() {
+	kept := w.Threats[:0]
+	for _, t := range w.Threats {
+		if t.despawnTick != 0 && w.Tick >= t.despawnTick {
+			continue
+		}
+		for len(t.levelChanges) > 0 && t.levelChanges[0].Tick <= w.Tick {
+			t.Level = t.levelChanges[0].Level
+			t.levelChanges = t.levelChanges[1:]
+		}
+		kept = append(kept, t)
+	}
+	w.Threats = kept
+
+	for len(w.pending) > 0 && w.pending[0].SpawnTick <= w.Tick {
+		st := w.pending[0]
+		w.pending = w.pending[1:]
+		w.Threats = append(w.Threats, Threat{
+			X:            st.X,
+			Y:            st.Y,
+			VX:           st.VX,
+			VY:           st.VY,
+			ID:           st.ID,
+			Level:        st.Level,
+			motion:       st.Motion.newModel(),
+			despawnTick:  st.DespawnTick,
+			levelChanges: st.LevelChanges,
+		})
+	}
 }